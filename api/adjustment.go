@@ -0,0 +1,34 @@
+package api
+
+import (
+	"time"
+)
+
+// URL: /1.0/adjustments
+// Access: admin
+
+// AdminAdjustment represents a manual score adjustment in the database.
+//
+// Author is the principal of the admin who created the adjustment.
+type AdminAdjustment struct {
+	AdminAdjustmentPost `yaml:",inline"`
+
+	ID         int64     `json:"id"          yaml:"id"`
+	SubmitTime time.Time `json:"submit_time" yaml:"submit_time"`
+	Author     string    `json:"author"      yaml:"author"`
+}
+
+// AdminAdjustmentPut represents the editable fields of a score adjustment in the database.
+//
+// Value is added to the team's score and may be negative for penalties.
+type AdminAdjustmentPut struct {
+	Value  int64  `json:"value"  yaml:"value"`
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// AdminAdjustmentPost represents the fields allowed when creating a new score adjustment.
+type AdminAdjustmentPost struct {
+	AdminAdjustmentPut `yaml:",inline"`
+
+	TeamID int64 `json:"team_id" yaml:"team_id"`
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// URL: /1.0/audit
+// Access: admin
+
+// AdminAuditEntry represents a recorded admin change.
+//
+// Before and After hold the state of the target (as returned by a GET on
+// the same URL) around the change. For creations, After is the provided
+// input instead. For deletions of a whole collection, both only hold the
+// number of entries ({"count": N}).
+//
+// Status is the HTTP status of the response, failed requests being recorded
+// as well.
+type AdminAuditEntry struct {
+	ID         int64           `json:"id"          yaml:"id"`
+	Principal  string          `json:"principal"   yaml:"principal"`
+	IP         string          `json:"ip"          yaml:"ip"`
+	Method     string          `json:"method"      yaml:"method"`
+	URL        string          `json:"url"         yaml:"url"`
+	Status     int             `json:"status"      yaml:"status"`
+	Before     json.RawMessage `json:"before"      yaml:"before"`
+	After      json.RawMessage `json:"after"       yaml:"after"`
+	SubmitTime time.Time       `json:"submit_time" yaml:"submit_time"`
+}
@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // URL: /1.0/config
 // Access: admin
 
@@ -20,17 +24,54 @@ type ConfigPut struct {
 }
 
 // ConfigDaemon represents the Daemon part of the Askgod configuration.
+//
+// TrustedProxies lists the subnets of the reverse proxies in front of Askgod.
+// The ForwardedHeader set by those ("X-Forwarded-For" by default, or
+// "Forwarded") is only honoured from them, and when set, PROXY protocol
+// headers (HAProxyHeader) are only honoured from them too.
+//
+// HTTPSClientCA enables the verification of client certificates issued by
+// the given CA. ClientCertificates then maps verified certificates to their
+// access, clients without a certificate still being allowed.
+//
+// ClusterCertificate and ClusterKey are the client certificate presented to
+// cluster peers verifying client certificates.
 type ConfigDaemon struct {
-	AllowedOrigins   []string `json:"allowed_origins"   yaml:"allowed_origins"`
-	ClusterPeers     []string `json:"cluster_peers"     yaml:"cluster_peers"`
-	HAProxyHeader    bool     `json:"haproxy_header"    yaml:"haproxy_header"`
-	HTTPPort         int      `json:"http_port"         yaml:"http_port"`
-	HTTPSPort        int      `json:"https_port"        yaml:"https_port"`
-	HTTPSCertificate string   `json:"https_certificate" yaml:"https_certificate"`
-	HTTPSKey         string   `json:"https_key"         yaml:"https_key"`
-	PrometheusPort   int      `json:"prometheus_port"   yaml:"prometheus_port"`
-	LogLevel         string   `json:"log_level"         yaml:"log_level"`
-	LogFile          string   `json:"log_file"          yaml:"log_file"`
+	AllowedOrigins     []string                  `json:"allowed_origins"     yaml:"allowed_origins"`
+	ClusterPeers       []string                  `json:"cluster_peers"       yaml:"cluster_peers"`
+	HAProxyHeader      bool                      `json:"haproxy_header"      yaml:"haproxy_header"`
+	TrustedProxies     []string                  `json:"trusted_proxies"     yaml:"trusted_proxies"`
+	ForwardedHeader    string                    `json:"forwarded_header"    yaml:"forwarded_header"`
+	HTTPPort           int                       `json:"http_port"           yaml:"http_port"`
+	HTTPSPort          int                       `json:"https_port"          yaml:"https_port"`
+	HTTPSCertificate   string                    `json:"https_certificate"   yaml:"https_certificate"`
+	HTTPSKey           string                    `json:"https_key"           yaml:"https_key"`
+	HTTPSClientCA      string                    `json:"https_client_ca"     yaml:"https_client_ca"`
+	ClientCertificates []ConfigClientCertificate `json:"client_certificates" yaml:"client_certificates"`
+	ClusterCertificate string                    `json:"cluster_certificate" yaml:"cluster_certificate"`
+	ClusterKey         string                    `json:"cluster_key"         yaml:"cluster_key"`
+	PrometheusPort     int                       `json:"prometheus_port"     yaml:"prometheus_port"`
+	LogLevel           string                    `json:"log_level"           yaml:"log_level"`
+	LogFile            string                    `json:"log_file"            yaml:"log_file"`
+}
+
+// Valid values for the Role field of ConfigClientCertificate (on top of the admin roles).
+const (
+	ClientCertificateRoleTeam = "team"
+	ClientCertificateRolePeer = "peer"
+)
+
+// ConfigClientCertificate maps a client certificate to its access.
+//
+// Name is matched against the certificate's subject common name and its
+// subject alternative names (DNS names, email addresses and URIs).
+//
+// Role is either one of the admin roles (see AdminKeyPost), "team" (the
+// team then being TeamID, which must be set) or "peer" for cluster peers.
+type ConfigClientCertificate struct {
+	Name   string `json:"name"    yaml:"name"`
+	Role   string `json:"role"    yaml:"role"`
+	TeamID int64  `json:"team_id" yaml:"team_id"`
 }
 
 // ConfigDatabase represents the Daemon part of the Askgod configuration.
@@ -44,12 +85,62 @@ type ConfigDatabase struct {
 	TLS         bool   `json:"tls"         yaml:"tls"`
 }
 
+// Valid values for the Mode field of ConfigScoring.
+const (
+	ScoringModeStatic  = "static"
+	ScoringModeDynamic = "dynamic"
+)
+
+// Valid values for the Tiebreak field of ConfigScoring.
+const (
+	TiebreakLastSubmit  = "last_submit"
+	TiebreakFirstReach  = "first_reach"
+	TiebreakFirstBloods = "first_bloods"
+	TiebreakShared      = "shared"
+)
+
 // ConfigScoring represents the Daemon part of the Askgod configuration.
+//
+// Mode selects how flags are valued. Valid values are:
+//   - "" or "static" => every team gets the flag's value
+//   - "dynamic"      => flags with a decay curve lose value as they get solved
+//
+// FirstBloodBonus lists the bonus points given to the first solvers of each
+// flag, the first entry going to the first team to solve it.
+//
+// RateLimitTeam and RateLimitIP are the number of invalid submissions allowed
+// per team and per IP within RateLimitWindow seconds (0 means unlimited).
+// Going over the limit locks out further submissions for RateLimitCooldown
+// seconds (required when either limit is set), doubling with every consecutive
+// lockout up to a year.
+//
+// FreezeAt freezes the public scoreboard and timeline at the given time, only
+// admins and the teams themselves seeing score changes past that point. A zero
+// value disables the freeze.
+//
+// StartTime and EndTime restrict flag submissions to the duration of the
+// event. A zero value means no restriction.
+//
+// Tiebreak selects how teams with the same score are ranked. Valid values are:
+//   - "" or "last_submit" => earliest last submission first
+//   - "first_reach"       => earliest to reach their current score first
+//   - "first_bloods"      => most first bloods first, then earliest last submission
+//   - "shared"            => teams with the same score share the same rank
 type ConfigScoring struct {
-	EventName  string   `json:"event_name"  yaml:"event_name"`
-	HideOthers bool     `json:"hide_others" yaml:"hide_others"`
-	ReadOnly   bool     `json:"read_only"   yaml:"read_only"`
-	PublicTags []string `json:"public_tags" yaml:"public_tags"`
+	EventName         string    `json:"event_name"          yaml:"event_name"`
+	HideOthers        bool      `json:"hide_others"         yaml:"hide_others"`
+	ReadOnly          bool      `json:"read_only"           yaml:"read_only"`
+	PublicTags        []string  `json:"public_tags"         yaml:"public_tags"`
+	Mode              string    `json:"mode"                yaml:"mode"`
+	FirstBloodBonus   []int64   `json:"first_blood_bonus"   yaml:"first_blood_bonus"`
+	RateLimitTeam     int64     `json:"rate_limit_team"     yaml:"rate_limit_team"`
+	RateLimitIP       int64     `json:"rate_limit_ip"       yaml:"rate_limit_ip"`
+	RateLimitWindow   int64     `json:"rate_limit_window"   yaml:"rate_limit_window"`
+	RateLimitCooldown int64     `json:"rate_limit_cooldown" yaml:"rate_limit_cooldown"`
+	FreezeAt          time.Time `json:"freeze_at"           yaml:"freeze_at"`
+	StartTime         time.Time `json:"start_time"          yaml:"start_time"`
+	EndTime           time.Time `json:"end_time"            yaml:"end_time"`
+	Tiebreak          string    `json:"tiebreak"            yaml:"tiebreak"`
 }
 
 // ConfigTeams represents the Daemon part of the Askgod configuration.
@@ -9,11 +9,15 @@ import (
 // Access: various
 
 // Event represents an event entry (over websocket).
+//
+// Principal is set on events caused by an admin request to the principal
+// of that admin. It's only sent to admins.
 type Event struct {
-	Server    string          `json:"server"    yaml:"server"`
-	Type      string          `json:"type"      yaml:"type"`
-	Timestamp time.Time       `json:"timestamp" yaml:"timestamp"`
-	Metadata  json.RawMessage `json:"metadata"  yaml:"metadata"`
+	Server    string          `json:"server"              yaml:"server"`
+	Type      string          `json:"type"                yaml:"type"`
+	Timestamp time.Time       `json:"timestamp"           yaml:"timestamp"`
+	Principal string          `json:"principal,omitempty" yaml:"principal,omitempty"`
+	Metadata  json.RawMessage `json:"metadata"            yaml:"metadata"`
 }
 
 // EventLogging represents a logging type event entry (admin only).
@@ -24,24 +28,44 @@ type EventLogging struct {
 }
 
 // EventFlag represents a flag submission event entry (admin only).
+//
+// Owner is set for "shared" submissions to the team the submitted flag variant belongs to.
+//
+// NearMiss is set for "near-miss" submissions to the way the input differs
+// from the closest flag ("whitespace", "prefix" or "typo").
 type EventFlag struct {
-	Team   AdminTeam  `json:"team"   yaml:"team"`
-	Flag   *AdminFlag `json:"flag"   yaml:"flag"`
-	Input  string     `json:"input"  yaml:"input"`
-	Value  int64      `json:"value"  yaml:"value"`
-	Type   string     `json:"type"   yaml:"type"`
-	Source string     `json:"source" yaml:"source"`
+	Team     AdminTeam  `json:"team"      yaml:"team"`
+	Flag     *AdminFlag `json:"flag"      yaml:"flag"`
+	Input    string     `json:"input"     yaml:"input"`
+	Value    int64      `json:"value"     yaml:"value"`
+	Type     string     `json:"type"      yaml:"type"`
+	Source   string     `json:"source"    yaml:"source"`
+	Owner    *AdminTeam `json:"owner"     yaml:"owner"`
+	NearMiss string     `json:"near_miss" yaml:"near_miss"`
 }
 
 // EventTimeline represents a change to the timeline (guest only).
 type EventTimeline struct {
-	TeamID int64               `json:"teamid" yaml:"teamid"`
-	Team   *TeamPut            `json:"team"   yaml:"team"`
-	Score  *TimelineEntryScore `json:"score"  yaml:"score"`
-	Type   string              `json:"type"   yaml:"type"`
+	TeamID   int64               `json:"teamid"   yaml:"teamid"`
+	Team     *TeamPut            `json:"team"     yaml:"team"`
+	Division string              `json:"division" yaml:"division"`
+	Score    *TimelineEntryScore `json:"score"    yaml:"score"`
+	Type     string              `json:"type"     yaml:"type"`
+}
+
+// EventFirstBlood represents a bonus awarded to one of the first solvers of a flag (guest only).
+type EventFirstBlood struct {
+	TeamID   int64             `json:"teamid"   yaml:"teamid"`
+	Team     *TeamPut          `json:"team"     yaml:"team"`
+	Division string            `json:"division" yaml:"division"`
+	Rank     int64             `json:"rank"     yaml:"rank"`
+	Value    int64             `json:"value"    yaml:"value"`
+	Tags     map[string]string `json:"tags"     yaml:"tags"`
 }
 
 // EventInternal represents an internal syncronisation event.
+//
+// Type is either "config-updated" or "teams-updated".
 type EventInternal struct {
 	Type string `json:"type" yaml:"type"`
 }
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"math"
 	"slices"
 	"time"
 )
@@ -46,6 +47,8 @@ func NormalizeSource(source string) (string, bool) {
 // Access: team
 
 // Flag represents a score entry as seen by the team.
+//
+// Trap is set for entries of trap flags.
 type Flag struct {
 	FlagPut `yaml:",inline"`
 
@@ -55,6 +58,8 @@ type Flag struct {
 	Value        int64     `json:"value"         yaml:"value"`
 	Source       string    `json:"source"        yaml:"source"`
 	SubmitTime   time.Time `json:"submit_time"   yaml:"submit_time"`
+	Type         string    `json:"type"          yaml:"type"`
+	Trap         bool      `json:"trap"          yaml:"trap"`
 }
 
 // FlagPut represents the editable fields of a team score entry.
@@ -89,15 +94,81 @@ type AdminFlag struct {
 }
 
 // AdminFlagPut represents the editable fields of a score entry in the database.
+//
+// When the scoring mode is set to dynamic and Decay is non-zero, the flag is
+// worth InitialValue for its first solve and decays towards MinimumValue,
+// which is reached once Decay more teams have solved it. Value is used
+// otherwise.
+//
+// Requires lists the IDs of the flags which must be solved by a team before
+// it's allowed to submit this one.
+//
+// ValidFrom and ValidUntil restrict the time window during which the flag
+// may be submitted. A zero value means no restriction.
+//
+// Trap marks a honeypot flag. Submitting it is reported as a "trap" and the
+// team gets tagged for review. A negative Value can be used as a penalty.
 type AdminFlagPut struct {
 	Flag         string            `json:"flag"          yaml:"flag"`
 	Value        int64             `json:"value"         yaml:"value"`
 	ReturnString string            `json:"return_string" yaml:"return_string"`
 	Description  string            `json:"description"   yaml:"description"`
 	Tags         map[string]string `json:"tags"          yaml:"tags"`
+	InitialValue int64             `json:"initial_value" yaml:"initial_value"`
+	MinimumValue int64             `json:"minimum_value" yaml:"minimum_value"`
+	Decay        int64             `json:"decay"         yaml:"decay"`
+	Requires     []int64           `json:"requires"      yaml:"requires"`
+	ValidFrom    time.Time         `json:"valid_from"    yaml:"valid_from"`
+	ValidUntil   time.Time         `json:"valid_until"   yaml:"valid_until"`
+	Trap         bool              `json:"trap"          yaml:"trap"`
+}
+
+// IsDynamic returns true if the flag has a decay curve configured.
+func (f AdminFlagPut) IsDynamic() bool {
+	return f.Decay > 0
+}
+
+// DynamicValue returns the value of the flag once it has been solved by the
+// provided number of teams. The value follows a parabolic curve going from
+// InitialValue on the first solve down to MinimumValue after Decay more solves.
+func (f AdminFlagPut) DynamicValue(solves int64) int64 {
+	if !f.IsDynamic() {
+		return f.Value
+	}
+
+	if solves <= 1 {
+		return f.InitialValue
+	}
+
+	if solves > f.Decay {
+		return f.MinimumValue
+	}
+
+	n := float64(solves - 1)
+	decay := float64(f.Decay)
+	value := float64(f.MinimumValue-f.InitialValue)/(decay*decay)*(n*n) + float64(f.InitialValue)
+
+	return max(int64(math.Ceil(value)), f.MinimumValue)
 }
 
 // AdminFlagPost represents the fields allowed when creating a new score entry.
 type AdminFlagPost struct {
 	AdminFlagPut `yaml:",inline"`
 }
+
+// URL: /1.0/flags/{id}/variants
+// Access: admin
+
+// AdminFlagVariant represents a team specific version of a flag.
+type AdminFlagVariant struct {
+	AdminFlagVariantPost `yaml:",inline"`
+
+	ID     int64 `json:"id"      yaml:"id"`
+	FlagID int64 `json:"flag_id" yaml:"flag_id"`
+}
+
+// AdminFlagVariantPost represents the fields allowed when creating a new flag variant.
+type AdminFlagVariantPost struct {
+	TeamID int64  `json:"team_id" yaml:"team_id"`
+	Flag   string `json:"flag"    yaml:"flag"`
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"time"
+)
+
+// URL: /1.0/team/hints
+// Access: team
+
+// Hint represents a hint as seen by the team.
+//
+// The text of the hint is only included once the team has unlocked it.
+type Hint struct {
+	ID          int64     `json:"id"          yaml:"id"`
+	FlagID      int64     `json:"flag_id"     yaml:"flag_id"`
+	Description string    `json:"description" yaml:"description"`
+	Cost        int64     `json:"cost"        yaml:"cost"`
+	Unlocked    bool      `json:"unlocked"    yaml:"unlocked"`
+	Text        string    `json:"text"        yaml:"text"`
+	UnlockTime  time.Time `json:"unlock_time" yaml:"unlock_time"`
+}
+
+// HintPost represents the fields used to unlock a hint.
+type HintPost struct {
+	Source string `json:"source" yaml:"source"`
+}
+
+// URL: /1.0/hints
+// Access: admin
+
+// AdminHint represents a hint entry in the database.
+type AdminHint struct {
+	AdminHintPost `yaml:",inline"`
+
+	ID int64 `json:"id" yaml:"id"`
+}
+
+// AdminHintPut represents the editable fields of a hint entry in the database.
+//
+// Cost is the number of points taken from a team when it unlocks the hint.
+type AdminHintPut struct {
+	FlagID      int64  `json:"flag_id"     yaml:"flag_id"`
+	Description string `json:"description" yaml:"description"`
+	Text        string `json:"text"        yaml:"text"`
+	Cost        int64  `json:"cost"        yaml:"cost"`
+}
+
+// AdminHintPost represents the fields allowed when creating a new hint entry.
+type AdminHintPost struct {
+	AdminHintPut `yaml:",inline"`
+}
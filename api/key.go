@@ -0,0 +1,43 @@
+package api
+
+import (
+	"time"
+)
+
+// URL: /1.0/keys
+// Access: admin
+
+// Valid values for the Role field of AdminKeyPost.
+const (
+	AdminRoleAdmin        = "admin"
+	AdminRoleAuditor      = "auditor"
+	AdminRoleFlagManager  = "flag_manager"
+	AdminRoleScoreManager = "score_manager"
+)
+
+// AdminKey represents a named admin API key.
+//
+// Only a hash of the key is stored, the key itself is returned once (in
+// Key) when it's issued.
+//
+// LastUsed is only updated once a minute.
+type AdminKey struct {
+	AdminKeyPost `yaml:",inline"`
+
+	ID        int64     `json:"id"            yaml:"id"`
+	CreatedAt time.Time `json:"created_at"    yaml:"created_at"`
+	LastUsed  time.Time `json:"last_used"     yaml:"last_used"`
+	Key       string    `json:"key,omitempty" yaml:"key,omitempty"`
+}
+
+// AdminKeyPost represents the fields allowed when issuing a new admin key.
+//
+// Role limits what the key may do. Valid values are:
+//   - "admin"         => full admin access
+//   - "auditor"       => read-only access
+//   - "flag_manager"  => read-only access, plus managing flags and hints
+//   - "score_manager" => read-only access, plus managing scores and adjustments
+type AdminKeyPost struct {
+	Name string `json:"name" yaml:"name"`
+	Role string `json:"role" yaml:"role"`
+}
@@ -4,6 +4,13 @@ import (
 	"time"
 )
 
+// Valid values for the Type field on score entries.
+const (
+	ScoreTypeSolve = "solve"
+	ScoreTypeBonus = "bonus"
+	ScoreTypeHint  = "hint"
+)
+
 // URL: /1.0/scores
 // Access: admin
 
@@ -22,10 +29,15 @@ type AdminScorePut struct {
 }
 
 // AdminScorePost represents the fields allowed when creating a new score entry.
+//
+// Type is either "solve" (the default) for the flag itself or "bonus" for
+// the extra points awarded to the first solvers of a flag. Entries of type
+// "hint" are created by askgod when a team unlocks a hint.
 type AdminScorePost struct {
 	AdminScorePut `yaml:",inline"`
 
 	TeamID int64  `json:"team_id" yaml:"team_id"`
 	FlagID int64  `json:"flag_id" yaml:"flag_id"`
 	Source string `json:"source"  yaml:"source"`
+	Type   string `json:"type"    yaml:"type"`
 }
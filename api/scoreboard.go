@@ -9,6 +9,7 @@ import (
 
 // ScoreboardEntry represents a line on the scoreboard.
 type ScoreboardEntry struct {
+	Rank           int64     `json:"rank"             yaml:"rank"`
 	Team           Team      `json:"team"             yaml:"team"`
 	Value          int64     `json:"value"            yaml:"value"`
 	LastSubmitTime time.Time `json:"last_submit_time" yaml:"last_submit_time"`
@@ -1,24 +1,45 @@
 package api
 
+import (
+	"time"
+)
+
+// Valid values for the EventState field of Status.
+const (
+	EventStatePending = "pending"
+	EventStateRunning = "running"
+	EventStateEnded   = "ended"
+)
+
 // URL: /1.0
 // Access: admin
 
 // Status represents the Askgod configuration.
+//
+// EventCountdown is the number of seconds until the next change of
+// EventState (0 if none is scheduled).
 type Status struct {
 	IsAdmin bool `json:"is_admin" yaml:"is_admin"`
 	IsTeam  bool `json:"is_team"  yaml:"is_team"`
 	IsGuest bool `json:"is_guest" yaml:"is_guest"`
 
-	EventName string `json:"event_name" yaml:"event_name"`
+	EventName      string    `json:"event_name"      yaml:"event_name"`
+	EventStart     time.Time `json:"event_start"     yaml:"event_start"`
+	EventEnd       time.Time `json:"event_end"       yaml:"event_end"`
+	EventState     string    `json:"event_state"     yaml:"event_state"`
+	EventCountdown int64     `json:"event_countdown" yaml:"event_countdown"`
 
 	Flags StatusFlags `json:"flags" yaml:"flags"`
 }
 
 // StatusFlags is a number of configuration flags that are useful to clients.
+//
+// BoardTiebreak is the policy used to rank teams with the same score (see ConfigScoring).
 type StatusFlags struct {
 	TeamSelfRegister bool `json:"team_self_register" yaml:"team_self_register"`
 	TeamSelfUpdate   bool `json:"team_self_update"   yaml:"team_self_update"`
 
-	BoardReadOnly   bool `json:"board_read_only"   yaml:"board_read_only"`
-	BoardHideOthers bool `json:"board_hide_others" yaml:"board_hide_others"`
+	BoardReadOnly   bool   `json:"board_read_only"   yaml:"board_read_only"`
+	BoardHideOthers bool   `json:"board_hide_others" yaml:"board_hide_others"`
+	BoardTiebreak   string `json:"board_tiebreak"    yaml:"board_tiebreak"`
 }
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"time"
+)
+
+// SubmissionNearMiss is the outcome of invalid submissions which are almost a valid flag.
+const SubmissionNearMiss = "near-miss"
+
+// URL: /1.0/submissions
+// Access: admin
+
+// AdminSubmission represents a single flag submission attempt.
+//
+// Outcome is one of "valid", "trap", "invalid", "near-miss", "duplicate",
+// "shared", "locked", "not-active", "expired", "ratelimited", "unconfigured"
+// or "error". FlagID is 0 when the input didn't match any flag, for near
+// misses it is the closest flag.
+type AdminSubmission struct {
+	ID         int64     `json:"id"          yaml:"id"`
+	TeamID     int64     `json:"team_id"     yaml:"team_id"`
+	FlagID     int64     `json:"flag_id"     yaml:"flag_id"`
+	IP         string    `json:"ip"          yaml:"ip"`
+	Input      string    `json:"input"       yaml:"input"`
+	Outcome    string    `json:"outcome"     yaml:"outcome"`
+	Source     string    `json:"source"      yaml:"source"`
+	SubmitTime time.Time `json:"submit_time" yaml:"submit_time"`
+}
+
+// URL: /1.0/submissions/near-misses
+// Access: admin
+
+// AdminNearMiss represents the near-miss submissions for a flag.
+//
+// Many teams submitting something close to a flag usually means the
+// challenge is broken.
+type AdminNearMiss struct {
+	FlagID         int64     `json:"flag_id"          yaml:"flag_id"`
+	Attempts       int64     `json:"attempts"         yaml:"attempts"`
+	Teams          int64     `json:"teams"            yaml:"teams"`
+	LastSubmitTime time.Time `json:"last_submit_time" yaml:"last_submit_time"`
+}
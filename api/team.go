@@ -7,7 +7,8 @@ package api
 type Team struct {
 	TeamPut `yaml:",inline"`
 
-	ID int64 `json:"id" yaml:"id"`
+	ID       int64  `json:"id"       yaml:"id"`
+	Division string `json:"division" yaml:"division"`
 }
 
 // TeamPut represents the editable fields of a team as seen by its members.
@@ -31,9 +32,10 @@ type AdminTeam struct {
 type AdminTeamPut struct {
 	TeamPut `yaml:",inline"`
 
-	Notes   string            `json:"notes"   yaml:"notes"`
-	Subnets string            `json:"subnets" yaml:"subnets"`
-	Tags    map[string]string `json:"tags"    yaml:"tags"`
+	Notes    string            `json:"notes"    yaml:"notes"`
+	Subnets  string            `json:"subnets"  yaml:"subnets"`
+	Tags     map[string]string `json:"tags"     yaml:"tags"`
+	Division string            `json:"division" yaml:"division"`
 }
 
 // AdminTeamPost represents the fields allowed when creating a new team.
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"time"
+)
+
+// URL: /1.0/teams/{id}/tokens
+// Access: admin
+
+// AdminTeamToken represents an API token identifying a team.
+//
+// Only a hash of the token is stored, the token itself is returned once
+// (in Token) when it's issued.
+//
+// LastUsed is only updated once a minute.
+type AdminTeamToken struct {
+	AdminTeamTokenPost `yaml:",inline"`
+
+	ID        int64     `json:"id"         yaml:"id"`
+	TeamID    int64     `json:"team_id"    yaml:"team_id"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+	LastUsed  time.Time `json:"last_used"  yaml:"last_used"`
+	Token     string    `json:"token,omitempty" yaml:"token,omitempty"`
+}
+
+// AdminTeamTokenPost represents the fields allowed when issuing a new team token.
+type AdminTeamTokenPost struct {
+	Description string `json:"description" yaml:"description"`
+}
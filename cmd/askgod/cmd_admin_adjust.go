@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v3"
+
+	"github.com/nsec/askgod/api"
+)
+
+func (c *client) cmdAdminAdjustAdd(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() < 3 {
+		_ = cli.ShowSubcommandHelp(cmd)
+
+		return nil
+	}
+
+	adjustment := api.AdminAdjustmentPost{}
+
+	teamid, err := strconv.ParseInt(cmd.Args().Get(0), 10, 64)
+	if err != nil {
+		return err
+	}
+
+	value, err := strconv.ParseInt(cmd.Args().Get(1), 10, 64)
+	if err != nil {
+		return err
+	}
+
+	adjustment.TeamID = teamid
+	adjustment.Value = value
+	adjustment.Reason = strings.Join(cmd.Args().Slice()[2:], " ")
+
+	err = c.queryStruct(ctx, "POST", "/adjustments", adjustment, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) cmdAdminAdjustDelete(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 1 {
+		_ = cli.ShowSubcommandHelp(cmd)
+
+		return nil
+	}
+
+	err := c.queryStruct(ctx, "DELETE", "/adjustments/"+cmd.Args().Get(0), nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) cmdAdminAdjustList(ctx context.Context, _ *cli.Command) error {
+	// Get the data
+	resp := []api.AdminAdjustment{}
+
+	err := c.queryStruct(ctx, "GET", "/adjustments", nil, &resp)
+	if err != nil {
+		return err
+	}
+
+	const layout = "2006/01/02 15:04"
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"ID", "TeamID", "Value", "Submit time", "Author", "Reason"})
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+
+	for _, entry := range resp {
+		table.Append([]string{
+			strconv.FormatInt(entry.ID, 10),
+			strconv.FormatInt(entry.TeamID, 10),
+			strconv.FormatInt(entry.Value, 10),
+			entry.SubmitTime.Local().Format(layout),
+			entry.Author,
+			entry.Reason,
+		})
+	}
+
+	table.Render()
+
+	return nil
+}
+
+func (c *client) cmdAdminAdjustUpdate(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() < 1 {
+		_ = cli.ShowSubcommandHelp(cmd)
+
+		return nil
+	}
+
+	adjustment := api.AdminAdjustment{}
+
+	err := c.queryStruct(ctx, "GET", "/adjustments/"+cmd.Args().Get(0), nil, &adjustment)
+	if err != nil {
+		return err
+	}
+
+	if cmd.NArg() > 1 {
+		for _, arg := range cmd.Args().Slice()[1:] {
+			err := setStructKey(&adjustment, arg)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = c.queryStruct(ctx, "PUT", "/adjustments/"+cmd.Args().Get(0), adjustment.AdminAdjustmentPut, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v3"
+
+	"github.com/nsec/askgod/api"
+)
+
+func (c *client) cmdAdminAudit(ctx context.Context, cmd *cli.Command) error {
+	// Prepare the filters
+	query := url.Values{}
+
+	for _, key := range []string{"principal", "target", "since", "until"} {
+		if cmd.String(key) != "" {
+			query.Set(key, cmd.String(key))
+		}
+	}
+
+	if cmd.Int64("limit") > 0 {
+		query.Set("limit", strconv.FormatInt(cmd.Int64("limit"), 10))
+	}
+
+	path := "/audit"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	// Get the data
+	resp := []api.AdminAuditEntry{}
+
+	err := c.queryStruct(ctx, "GET", path, nil, &resp)
+	if err != nil {
+		return err
+	}
+
+	const layout = "2006/01/02 15:04:05"
+
+	header := []string{"ID", "Time", "Principal", "IP", "Method", "URL", "Status"}
+	if cmd.Bool("changes") {
+		header = append(header, "Before", "After")
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+
+	for _, entry := range resp {
+		row := []string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.SubmitTime.Local().Format(layout),
+			entry.Principal,
+			entry.IP,
+			entry.Method,
+			entry.URL,
+			strconv.Itoa(entry.Status),
+		}
+
+		if cmd.Bool("changes") {
+			row = append(row, string(entry.Before), string(entry.After))
+		}
+
+		table.Append(row)
+	}
+
+	table.Render()
+
+	return nil
+}
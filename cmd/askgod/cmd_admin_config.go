@@ -46,3 +46,12 @@ func (c *client) cmdAdminConfig(ctx context.Context, cmd *cli.Command) error {
 
 	return nil
 }
+
+func (c *client) cmdAdminRevealScoreboard(ctx context.Context, _ *cli.Command) error {
+	err := c.queryStruct(ctx, "POST", "/scoreboard/reveal", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
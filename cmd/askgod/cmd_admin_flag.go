@@ -9,6 +9,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/urfave/cli/v3"
@@ -108,16 +109,41 @@ func (c *client) cmdAdminListFlags(ctx context.Context, _ *cli.Command) error {
 		return err
 	}
 
+	const layout = "2006/01/02 15:04"
+
+	validTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+
+		return t.Local().Format(layout)
+	}
+
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"ID", "Flag", "Value", "Return string", "Description", "Tags"})
+	table.SetHeader([]string{"ID", "Flag", "Value", "Decay", "Requires", "Valid from", "Valid until", "Trap", "Return string", "Description", "Tags"})
 	table.SetBorder(false)
 	table.SetAutoWrapText(false)
 
 	for _, entry := range resp {
+		decay := ""
+		if entry.IsDynamic() {
+			decay = fmt.Sprintf("%d -> %d (%d solves)", entry.InitialValue, entry.MinimumValue, entry.Decay)
+		}
+
+		requires := make([]string, 0, len(entry.Requires))
+		for _, id := range entry.Requires {
+			requires = append(requires, strconv.FormatInt(id, 10))
+		}
+
 		table.Append([]string{
 			strconv.FormatInt(entry.ID, 10),
 			entry.Flag,
 			strconv.FormatInt(entry.Value, 10),
+			decay,
+			strings.Join(requires, ","),
+			validTime(entry.ValidFrom),
+			validTime(entry.ValidUntil),
+			strconv.FormatBool(entry.Trap),
 			entry.ReturnString,
 			entry.Description,
 			utils.PackTags(entry.Tags),
@@ -129,6 +155,101 @@ func (c *client) cmdAdminListFlags(ctx context.Context, _ *cli.Command) error {
 	return nil
 }
 
+func (c *client) cmdAdminGenerateFlagVariants(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 1 {
+		_ = cli.ShowSubcommandHelp(cmd)
+
+		return nil
+	}
+
+	err := c.queryStruct(ctx, "POST", "/flags/"+cmd.Args().Get(0)+"/variants?generate=1", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) cmdAdminImportFlagVariants(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() < 2 {
+		_ = cli.ShowSubcommandHelp(cmd)
+
+		return nil
+	}
+
+	// Flush all existing entries
+	if cmd.Bool("flush") {
+		reader := bufio.NewReader(os.Stdin)
+		_, _ = fmt.Print("Flush all variants of the flag (yes/no): ") //nolint:forbidigo
+		input, _ := reader.ReadString('\n')
+
+		input = strings.TrimSuffix(input, "\n")
+		if strings.TrimSpace(strings.ToLower(input)) != "yes" {
+			return errors.New("user aborted flush operation")
+		}
+
+		err := c.queryStruct(ctx, "DELETE", "/flags/"+cmd.Args().Get(0)+"/variants?empty=1", nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Read the file
+	content, err := os.ReadFile(cmd.Args().Get(1))
+	if err != nil {
+		return err
+	}
+
+	// Parse the JSON file
+	variants := []api.AdminFlagVariantPost{}
+
+	err = json.Unmarshal(content, &variants)
+	if err != nil {
+		return err
+	}
+
+	// Create the variants
+	err = c.queryStruct(ctx, "POST", "/flags/"+cmd.Args().Get(0)+"/variants", variants, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) cmdAdminListFlagVariants(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 1 {
+		_ = cli.ShowSubcommandHelp(cmd)
+
+		return nil
+	}
+
+	// Get the data
+	resp := []api.AdminFlagVariant{}
+
+	err := c.queryStruct(ctx, "GET", "/flags/"+cmd.Args().Get(0)+"/variants", nil, &resp)
+	if err != nil {
+		return err
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"ID", "Team ID", "Flag"})
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+
+	for _, entry := range resp {
+		table.Append([]string{
+			strconv.FormatInt(entry.ID, 10),
+			strconv.FormatInt(entry.TeamID, 10),
+			entry.Flag,
+		})
+	}
+
+	table.Render()
+
+	return nil
+}
+
 func (c *client) cmdAdminUpdateFlag(ctx context.Context, cmd *cli.Command) error {
 	if cmd.NArg() < 1 {
 		_ = cli.ShowSubcommandHelp(cmd)
@@ -152,7 +273,12 @@ func (c *client) cmdAdminUpdateFlag(ctx context.Context, cmd *cli.Command) error
 		}
 	}
 
-	err = c.queryStruct(ctx, "PUT", "/flags/"+cmd.Args().Get(0), flag.AdminFlagPut, nil)
+	path := "/flags/" + cmd.Args().Get(0)
+	if cmd.Bool("propagate") {
+		path += "?propagate=1"
+	}
+
+	err = c.queryStruct(ctx, "PUT", path, flag.AdminFlagPut, nil)
 	if err != nil {
 		return err
 	}
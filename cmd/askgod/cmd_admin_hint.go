@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v3"
+
+	"github.com/nsec/askgod/api"
+)
+
+func (c *client) cmdAdminAddHint(ctx context.Context, cmd *cli.Command) error {
+	hint := api.AdminHintPost{}
+
+	if cmd.NArg() > 0 {
+		for _, arg := range cmd.Args().Slice() {
+			err := setStructKey(&hint, arg)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err := c.queryStruct(ctx, "POST", "/hints", hint, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) cmdAdminDeleteHint(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 1 {
+		_ = cli.ShowSubcommandHelp(cmd)
+
+		return nil
+	}
+
+	err := c.queryStruct(ctx, "DELETE", "/hints/"+cmd.Args().Get(0), nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) cmdAdminListHints(ctx context.Context, _ *cli.Command) error {
+	// Get the data
+	resp := []api.AdminHint{}
+
+	err := c.queryStruct(ctx, "GET", "/hints", nil, &resp)
+	if err != nil {
+		return err
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"ID", "Flag ID", "Description", "Cost", "Text"})
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+
+	for _, entry := range resp {
+		table.Append([]string{
+			strconv.FormatInt(entry.ID, 10),
+			strconv.FormatInt(entry.FlagID, 10),
+			entry.Description,
+			strconv.FormatInt(entry.Cost, 10),
+			entry.Text,
+		})
+	}
+
+	table.Render()
+
+	return nil
+}
+
+func (c *client) cmdAdminUpdateHint(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() < 1 {
+		_ = cli.ShowSubcommandHelp(cmd)
+
+		return nil
+	}
+
+	hint := api.AdminHint{}
+
+	err := c.queryStruct(ctx, "GET", "/hints/"+cmd.Args().Get(0), nil, &hint)
+	if err != nil {
+		return err
+	}
+
+	if cmd.NArg() > 1 {
+		for _, arg := range cmd.Args().Slice()[1:] {
+			err := setStructKey(&hint, arg)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = c.queryStruct(ctx, "PUT", "/hints/"+cmd.Args().Get(0), hint.AdminHintPut, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
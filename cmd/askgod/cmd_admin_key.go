@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v3"
+
+	"github.com/nsec/askgod/api"
+)
+
+func (c *client) cmdAdminAddKey(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 2 {
+		_ = cli.ShowSubcommandHelp(cmd)
+
+		return nil
+	}
+
+	key := api.AdminKeyPost{
+		Name: cmd.Args().Get(0),
+		Role: cmd.Args().Get(1),
+	}
+
+	resp := api.AdminKey{}
+
+	err := c.queryStruct(ctx, "POST", "/keys", key, &resp)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Printf("Key %d: %s\n", resp.ID, resp.Key) //nolint:forbidigo
+
+	return nil
+}
+
+func (c *client) cmdAdminDeleteKey(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 1 {
+		_ = cli.ShowSubcommandHelp(cmd)
+
+		return nil
+	}
+
+	err := c.queryStruct(ctx, "DELETE", "/keys/"+cmd.Args().Get(0), nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) cmdAdminListKeys(ctx context.Context, _ *cli.Command) error {
+	// Get the data
+	resp := []api.AdminKey{}
+
+	err := c.queryStruct(ctx, "GET", "/keys", nil, &resp)
+	if err != nil {
+		return err
+	}
+
+	const layout = "2006/01/02 15:04"
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"ID", "Name", "Role", "Created", "Last used"})
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+
+	for _, entry := range resp {
+		lastUsed := ""
+		if !entry.LastUsed.IsZero() {
+			lastUsed = entry.LastUsed.Local().Format(layout)
+		}
+
+		table.Append([]string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.Name,
+			entry.Role,
+			entry.CreatedAt.Local().Format(layout),
+			lastUsed,
+		})
+	}
+
+	table.Render()
+
+	return nil
+}
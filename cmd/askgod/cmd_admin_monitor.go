@@ -134,9 +134,37 @@ func (c *client) cmdAdminMonitorFlags(_ context.Context, cmd *cli.Command) error
 		case "valid":
 			_, _ = fmt.Printf("[%s][%s] Team \"%s\" (%s) scored %d points with \"%s\" (id=%d) (%s) [%s]\n", //nolint:forbidigo
 				event.Server, event.Timestamp.Local().Format(layout), score.Team.Name, team, score.Value, score.Input, score.Flag.ID, utils.PackTags(score.Flag.Tags), score.Source)
+		case "trap":
+			_, _ = fmt.Printf("[%s][%s] Team \"%s\" (%s) TRIGGERED TRAP \"%s\" (id=%d) for %d points (%s) [%s]\n", //nolint:forbidigo
+				event.Server, event.Timestamp.Local().Format(layout), score.Team.Name, team, score.Input, score.Flag.ID, score.Value, utils.PackTags(score.Flag.Tags), score.Source)
 		case "duplicate":
 			_, _ = fmt.Printf("[%s][%s] Team \"%s\" (%s) re-submitted \"%s\" (id=%d) (%s) [%s]\n", //nolint:forbidigo
 				event.Server, event.Timestamp.Local().Format(layout), score.Team.Name, team, score.Input, score.Flag.ID, utils.PackTags(score.Flag.Tags), score.Source)
+		case "locked":
+			_, _ = fmt.Printf("[%s][%s] Team \"%s\" (%s) submitted locked flag \"%s\" (id=%d) (%s) [%s]\n", //nolint:forbidigo
+				event.Server, event.Timestamp.Local().Format(layout), score.Team.Name, team, score.Input, score.Flag.ID, utils.PackTags(score.Flag.Tags), score.Source)
+		case "not-active", "expired":
+			_, _ = fmt.Printf("[%s][%s] Team \"%s\" (%s) submitted %s flag \"%s\" (id=%d) (%s) [%s]\n", //nolint:forbidigo
+				event.Server, event.Timestamp.Local().Format(layout), score.Team.Name, team, score.Type, score.Input, score.Flag.ID, utils.PackTags(score.Flag.Tags), score.Source)
+		case "shared":
+			owner := "unknown"
+			if score.Owner != nil {
+				owner = fmt.Sprintf("\"%s\" (id=%d)", score.Owner.Name, score.Owner.ID)
+			}
+
+			_, _ = fmt.Printf("[%s][%s] Team \"%s\" (%s) submitted the variant of team %s for \"%s\" (id=%d) (%s) [%s]\n", //nolint:forbidigo
+				event.Server, event.Timestamp.Local().Format(layout), score.Team.Name, team, owner, score.Input, score.Flag.ID, utils.PackTags(score.Flag.Tags), score.Source)
+		case "hint":
+			_, _ = fmt.Printf("[%s][%s] Team \"%s\" (%s) unlocked a hint for %d points for flag id=%d (%s) [%s]\n", //nolint:forbidigo
+				event.Server, event.Timestamp.Local().Format(layout), score.Team.Name, team, -score.Value, score.Flag.ID, utils.PackTags(score.Flag.Tags), score.Source)
+		case api.SubmissionNearMiss:
+			flagID := int64(0)
+			if score.Flag != nil {
+				flagID = score.Flag.ID
+			}
+
+			_, _ = fmt.Printf("[%s][%s] Team \"%s\" (%s) submitted near-miss \"%s\" close to flag id=%d (%s) [%s]\n", //nolint:forbidigo
+				event.Server, event.Timestamp.Local().Format(layout), score.Team.Name, team, score.Input, flagID, score.NearMiss, score.Source)
 		case "invalid":
 			_, _ = fmt.Printf("[%s][%s] Team \"%s\" (%s) submitted invalid flag \"%s\" [%s]\n", //nolint:forbidigo
 				event.Server, event.Timestamp.Local().Format(layout), score.Team.Name, team, score.Input, score.Source)
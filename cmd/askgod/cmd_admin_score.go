@@ -112,7 +112,7 @@ func (c *client) cmdAdminListScores(ctx context.Context, cmd *cli.Command) error
 	const layout = "2006/01/02 15:04"
 
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"ID", "TeamID", "FlagID", "Value", "Submit time", "Source", "Notes"})
+	table.SetHeader([]string{"ID", "TeamID", "FlagID", "Type", "Value", "Submit time", "Source", "Notes"})
 	table.SetBorder(false)
 	table.SetAutoWrapText(false)
 
@@ -125,6 +125,7 @@ func (c *client) cmdAdminListScores(ctx context.Context, cmd *cli.Command) error
 			strconv.FormatInt(entry.ID, 10),
 			strconv.FormatInt(entry.TeamID, 10),
 			strconv.FormatInt(entry.FlagID, 10),
+			entry.Type,
 			strconv.FormatInt(entry.Value, 10),
 			entry.SubmitTime.Local().Format(layout),
 			entry.Source,
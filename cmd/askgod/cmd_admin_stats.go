@@ -64,12 +64,21 @@ func (c *client) cmdAdminStats(ctx context.Context, _ *cli.Command) error {
 		aiCount    int
 	}
 
+	// Trap flags aren't meant to be solved, leave them out of the statistics
 	stats := make(map[int64]*flagStats, len(flags))
 	for _, flag := range flags {
+		if flag.Trap {
+			continue
+		}
+
 		stats[flag.ID] = &flagStats{teams: make(map[int64]struct{})}
 	}
 
 	for _, score := range scores {
+		if score.Type != "" && score.Type != api.ScoreTypeSolve {
+			continue
+		}
+
 		fs, ok := stats[score.FlagID]
 		if !ok {
 			continue
@@ -97,6 +106,7 @@ func (c *client) cmdAdminStats(ctx context.Context, _ *cli.Command) error {
 	table.SetHeader([]string{
 		"FlagID",
 		"Value",
+		"Trap",
 		"First blood",
 		"Last solve",
 		"Solve %",
@@ -107,14 +117,18 @@ func (c *client) cmdAdminStats(ctx context.Context, _ *cli.Command) error {
 	table.SetAutoWrapText(false)
 
 	for _, flag := range flags {
-		fs := stats[flag.ID]
+		fs, ok := stats[flag.ID]
 
 		firstBlood := "-"
 		lastSolve := "-"
 		solvePct := "0%"
 		aiPct := "-"
 
-		if fs.hasSolve {
+		trap := ""
+		if !ok {
+			trap = "yes"
+			solvePct = "-"
+		} else if fs.hasSolve {
 			firstBlood = fs.firstBlood.Local().Format(layout)
 			lastSolve = fs.lastSolve.Local().Format(layout)
 
@@ -130,6 +144,7 @@ func (c *client) cmdAdminStats(ctx context.Context, _ *cli.Command) error {
 		table.Append([]string{
 			strconv.FormatInt(flag.ID, 10),
 			strconv.FormatInt(flag.Value, 10),
+			trap,
 			firstBlood,
 			lastSolve,
 			solvePct,
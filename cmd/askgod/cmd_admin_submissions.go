@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v3"
+
+	"github.com/nsec/askgod/api"
+)
+
+func (c *client) cmdAdminSubmissions(ctx context.Context, cmd *cli.Command) error {
+	// Prepare the filters
+	query := url.Values{}
+
+	for _, key := range []string{"ip", "outcome", "source", "since", "until"} {
+		if cmd.String(key) != "" {
+			query.Set(key, cmd.String(key))
+		}
+	}
+
+	for key, flag := range map[string]string{"team_id": "team", "flag_id": "flag", "limit": "limit"} {
+		if cmd.Int64(flag) > 0 {
+			query.Set(key, strconv.FormatInt(cmd.Int64(flag), 10))
+		}
+	}
+
+	path := "/submissions"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	// Get the data
+	resp := []api.AdminSubmission{}
+
+	err := c.queryStruct(ctx, "GET", path, nil, &resp)
+	if err != nil {
+		return err
+	}
+
+	const layout = "2006/01/02 15:04:05"
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"ID", "Submit time", "TeamID", "IP", "Source", "Outcome", "FlagID", "Input"})
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+
+	for _, entry := range resp {
+		flagID := ""
+		if entry.FlagID > 0 {
+			flagID = strconv.FormatInt(entry.FlagID, 10)
+		}
+
+		table.Append([]string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.SubmitTime.Local().Format(layout),
+			strconv.FormatInt(entry.TeamID, 10),
+			entry.IP,
+			entry.Source,
+			entry.Outcome,
+			flagID,
+			entry.Input,
+		})
+	}
+
+	table.Render()
+
+	return nil
+}
+
+func (c *client) cmdAdminNearMisses(ctx context.Context, _ *cli.Command) error {
+	// Get the data
+	resp := []api.AdminNearMiss{}
+
+	err := c.queryStruct(ctx, "GET", "/submissions/near-misses", nil, &resp)
+	if err != nil {
+		return err
+	}
+
+	const layout = "2006/01/02 15:04:05"
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"FlagID", "Teams", "Attempts", "Last attempt"})
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+
+	for _, entry := range resp {
+		table.Append([]string{
+			strconv.FormatInt(entry.FlagID, 10),
+			strconv.FormatInt(entry.Teams, 10),
+			strconv.FormatInt(entry.Attempts, 10),
+			entry.LastSubmitTime.Local().Format(layout),
+		})
+	}
+
+	table.Render()
+
+	return nil
+}
@@ -109,7 +109,7 @@ func (c *client) cmdAdminListTeams(ctx context.Context, _ *cli.Command) error {
 	}
 
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"ID", "Name", "Country", "Website", "Subnets", "Notes", "Tags"})
+	table.SetHeader([]string{"ID", "Name", "Country", "Website", "Division", "Subnets", "Notes", "Tags"})
 	table.SetBorder(false)
 	table.SetAutoWrapText(false)
 
@@ -119,6 +119,7 @@ func (c *client) cmdAdminListTeams(ctx context.Context, _ *cli.Command) error {
 			entry.Name,
 			entry.Country,
 			entry.Website,
+			entry.Division,
 			entry.Subnets,
 			entry.Notes,
 			utils.PackTags(entry.Tags),
@@ -160,3 +161,82 @@ func (c *client) cmdAdminUpdateTeam(ctx context.Context, cmd *cli.Command) error
 
 	return nil
 }
+
+func (c *client) cmdAdminIssueTeamToken(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() < 1 || cmd.NArg() > 2 {
+		_ = cli.ShowSubcommandHelp(cmd)
+
+		return nil
+	}
+
+	token := api.AdminTeamTokenPost{
+		Description: cmd.Args().Get(1),
+	}
+
+	resp := api.AdminTeamToken{}
+
+	err := c.queryStruct(ctx, "POST", "/teams/"+cmd.Args().Get(0)+"/tokens", token, &resp)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Printf("Token %d: %s\n", resp.ID, resp.Token) //nolint:forbidigo
+
+	return nil
+}
+
+func (c *client) cmdAdminListTeamTokens(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 1 {
+		_ = cli.ShowSubcommandHelp(cmd)
+
+		return nil
+	}
+
+	// Get the data
+	resp := []api.AdminTeamToken{}
+
+	err := c.queryStruct(ctx, "GET", "/teams/"+cmd.Args().Get(0)+"/tokens", nil, &resp)
+	if err != nil {
+		return err
+	}
+
+	const layout = "2006/01/02 15:04"
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"ID", "Description", "Created", "Last used"})
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+
+	for _, entry := range resp {
+		lastUsed := ""
+		if !entry.LastUsed.IsZero() {
+			lastUsed = entry.LastUsed.Local().Format(layout)
+		}
+
+		table.Append([]string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.Description,
+			entry.CreatedAt.Local().Format(layout),
+			lastUsed,
+		})
+	}
+
+	table.Render()
+
+	return nil
+}
+
+func (c *client) cmdAdminRevokeTeamToken(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 2 {
+		_ = cli.ShowSubcommandHelp(cmd)
+
+		return nil
+	}
+
+	err := c.queryStruct(ctx, "DELETE", "/teams/"+cmd.Args().Get(0)+"/tokens/"+cmd.Args().Get(1), nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -54,6 +54,7 @@ func (c *client) cmdDetails(ctx context.Context, cmd *cli.Command) error {
 	table.Append([]string{"NAME", val(resp.Name)})
 	table.Append([]string{"COUNTRY", val(resp.Country)})
 	table.Append([]string{"WEBSITE", val(resp.Website)})
+	table.Append([]string{"DIVISION", val(resp.Division)})
 
 	table.Render()
 
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v3"
+
+	"github.com/nsec/askgod/api"
+)
+
+func (c *client) cmdHintList(ctx context.Context, _ *cli.Command) error {
+	// Get the data
+	resp := []api.Hint{}
+
+	err := c.queryStruct(ctx, "GET", "/team/hints", nil, &resp)
+	if err != nil {
+		return err
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"ID", "Flag ID", "Description", "Cost", "Unlocked", "Text"})
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+
+	for _, hint := range resp {
+		table.Append([]string{
+			strconv.FormatInt(hint.ID, 10),
+			strconv.FormatInt(hint.FlagID, 10),
+			hint.Description,
+			strconv.FormatInt(hint.Cost, 10),
+			strconv.FormatBool(hint.Unlocked),
+			hint.Text,
+		})
+	}
+
+	table.Render()
+
+	return nil
+}
+
+func (c *client) cmdHintUnlock(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 1 {
+		_ = cli.ShowSubcommandHelp(cmd)
+
+		return nil
+	}
+
+	// Prepare the input
+	hint := api.HintPost{Source: api.SourceCLI}
+	if hasAgentInEnvVariable() {
+		hint.Source = api.SourceCLIAgent
+	}
+
+	// Unlock the hint
+	resp := api.Hint{}
+
+	err := c.queryStruct(ctx, "POST", "/team/hints/"+cmd.Args().Get(0), hint, &resp)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Printf("Hint unlocked, your team was charged %d points.\n", resp.Cost) //nolint:forbidigo
+	_, _ = fmt.Printf("Hint: %s\n", resp.Text)                                        //nolint:forbidigo
+
+	return nil
+}
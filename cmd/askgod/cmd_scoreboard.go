@@ -1,12 +1,16 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"slices"
 	"strconv"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/urfave/cli/v3"
@@ -19,33 +23,68 @@ func (c *client) cmdScoreboard(ctx context.Context, cmd *cli.Command) error {
 
 	const layout = "2006/01/02 15:04"
 
-	byPointsAndLastSubmitTime := func(a api.ScoreboardEntry, b api.ScoreboardEntry) int {
-		if a.Value != b.Value {
-			if a.Value < b.Value {
-				return 1
-			}
+	// Prepare the filters
+	query := url.Values{}
 
-			return -1
-		}
+	if cmd.String("tag") != "" {
+		query.Set("tag", cmd.String("tag"))
+	}
 
-		if a.LastSubmitTime.Equal(b.LastSubmitTime) {
-			return 0
+	if cmd.String("division") != "" {
+		query.Set("division", cmd.String("division"))
+	}
+
+	if cmd.String("at") != "" {
+		if cmd.Bool("live") {
+			return errors.New("the at and live options can't be combined")
 		}
 
-		if a.LastSubmitTime.Before(b.LastSubmitTime) {
-			return -1
+		at, err := time.Parse(time.RFC3339, cmd.String("at"))
+		if err != nil {
+			return err
 		}
 
-		return 1
+		query.Set("at", at.Format(time.RFC3339))
+	}
+
+	path := "/scoreboard"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	byRank := func(a api.ScoreboardEntry, b api.ScoreboardEntry) int {
+		return cmp.Compare(a.Rank, b.Rank)
 	}
 
 	drawTable := func(board []api.ScoreboardEntry) {
+		// Show the division rankings alongside the overall one
+		showDivisions := false
+
+		for _, entry := range board {
+			if entry.Team.Division != "" {
+				showDivisions = true
+
+				break
+			}
+		}
+
 		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"Ranking", "Team", "Points", "Last submit"})
+		if showDivisions {
+			table.SetHeader([]string{"Ranking", "Division", "Team", "Points", "Last submit"})
+		} else {
+			table.SetHeader([]string{"Ranking", "Team", "Points", "Last submit"})
+		}
+
 		table.SetBorder(false)
 		table.SetAutoWrapText(false)
 
-		rank := 1
+		type divisionRank struct {
+			count int64
+			last  int64
+			rank  int64
+		}
+
+		divisionRanks := map[string]*divisionRank{}
 
 		for _, entry := range board {
 			lastSubmitTime := "never"
@@ -53,14 +92,38 @@ func (c *client) cmdScoreboard(ctx context.Context, cmd *cli.Command) error {
 				lastSubmitTime = entry.LastSubmitTime.Local().Format(layout)
 			}
 
-			table.Append([]string{
-				strconv.Itoa(rank),
+			line := []string{strconv.FormatInt(entry.Rank, 10)}
+
+			if showDivisions {
+				division := ""
+
+				if entry.Team.Division != "" {
+					// Teams sharing an overall rank also share their division rank
+					divRank, ok := divisionRanks[entry.Team.Division]
+					if !ok {
+						divRank = &divisionRank{}
+						divisionRanks[entry.Team.Division] = divRank
+					}
+
+					divRank.count++
+					if entry.Rank != divRank.last {
+						divRank.rank = divRank.count
+						divRank.last = entry.Rank
+					}
+
+					division = fmt.Sprintf("%s #%d", entry.Team.Division, divRank.rank)
+				}
+
+				line = append(line, division)
+			}
+
+			line = append(line,
 				fmt.Sprintf("<%s> %s ", entry.Team.Country, entry.Team.Name),
 				strconv.FormatInt(entry.Value, 10),
 				lastSubmitTime,
-			})
+			)
 
-			rank++
+			table.Append(line)
 		}
 
 		table.Render()
@@ -68,18 +131,57 @@ func (c *client) cmdScoreboard(ctx context.Context, cmd *cli.Command) error {
 
 	if !cmd.Bool("live") {
 		// Get the data
-		err := c.queryStruct(ctx, "GET", "/scoreboard", nil, &board)
+		err := c.queryStruct(ctx, "GET", path, nil, &board)
 		if err != nil {
 			return err
 		}
 
-		slices.SortFunc(board, byPointsAndLastSubmitTime)
+		slices.SortFunc(board, byRank)
 
 		drawTable(board)
 
 		return nil
 	}
 
+	// Get the tie-breaking policy (only some of them can be applied locally)
+	status := api.Status{}
+
+	err := c.queryStruct(ctx, "GET", "", nil, &status)
+	if err != nil {
+		return err
+	}
+
+	tiebreak := status.Flags.BoardTiebreak
+	localRanks := len(query) == 0 && slices.Contains([]string{"", api.TiebreakLastSubmit, api.TiebreakShared}, tiebreak)
+
+	rankBoard := func(board []api.ScoreboardEntry) {
+		slices.SortStableFunc(board, func(a api.ScoreboardEntry, b api.ScoreboardEntry) int {
+			if a.Value != b.Value {
+				return cmp.Compare(b.Value, a.Value)
+			}
+
+			// Teams which never submitted anything come last
+			switch {
+			case a.LastSubmitTime.Equal(b.LastSubmitTime):
+				return 0
+			case a.LastSubmitTime.IsZero():
+				return 1
+			case b.LastSubmitTime.IsZero():
+				return -1
+			}
+
+			return a.LastSubmitTime.Compare(b.LastSubmitTime)
+		})
+
+		for i := range board {
+			board[i].Rank = int64(i + 1)
+
+			if tiebreak == api.TiebreakShared && i > 0 && board[i].Value == board[i-1].Value {
+				board[i].Rank = board[i-1].Rank
+			}
+		}
+	}
+
 	// Setup websocket connection
 	chReady := make(chan bool, 1)
 	chUpdate := make(chan bool, 1)
@@ -119,17 +221,24 @@ func (c *client) cmdScoreboard(ctx context.Context, cmd *cli.Command) error {
 				continue
 			}
 
-			// Server requests a reload of the data
-			if entry.Type == "reload" {
+			// Server requests a reload of the data (filtered boards and some
+			// tie-breaking policies can't be updated from the events)
+			if entry.Type == "reload" || !localRanks {
 				// Get a new dump
 				board = []api.ScoreboardEntry{}
 
-				err = c.queryStruct(ctx, "GET", "/scoreboard", nil, &board)
+				err = c.queryStruct(ctx, "GET", path, nil, &board)
 				if err != nil {
 					close(chUpdate)
 
 					break
 				}
+
+				slices.SortFunc(board, byRank)
+
+				chUpdate <- true
+
+				continue
 			}
 
 			// Try to find the line
@@ -153,7 +262,7 @@ func (c *client) cmdScoreboard(ctx context.Context, cmd *cli.Command) error {
 
 				// Team may have changed
 				if entry.Team != nil {
-					board[i].Team = api.Team{TeamPut: *entry.Team, ID: entry.TeamID}
+					board[i].Team = api.Team{TeamPut: *entry.Team, ID: entry.TeamID, Division: entry.Division}
 				}
 
 				// Score may have changed
@@ -162,15 +271,13 @@ func (c *client) cmdScoreboard(ctx context.Context, cmd *cli.Command) error {
 					board[i].LastSubmitTime = event.Timestamp
 				}
 
-				found = true
-
 				break
 			}
 
 			// Add a new line
 			if !found && entry.Team != nil {
 				newEntry := api.ScoreboardEntry{
-					Team:           api.Team{TeamPut: *entry.Team, ID: entry.TeamID},
+					Team:           api.Team{TeamPut: *entry.Team, ID: entry.TeamID, Division: entry.Division},
 					LastSubmitTime: event.Timestamp,
 				}
 
@@ -181,15 +288,15 @@ func (c *client) cmdScoreboard(ctx context.Context, cmd *cli.Command) error {
 				board = append(board, newEntry)
 			}
 
-			// Sort the updated board ourselves
-			slices.SortFunc(board, byPointsAndLastSubmitTime)
+			// Rank the updated board ourselves
+			rankBoard(board)
 
 			chUpdate <- true
 		}
 	}()
 
 	// Get the initial data
-	err = c.queryStruct(ctx, "GET", "/scoreboard", nil, &board)
+	err = c.queryStruct(ctx, "GET", path, nil, &board)
 	if err != nil {
 		return err
 	}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/goccy/go-yaml"
 	"github.com/urfave/cli/v3"
@@ -26,5 +27,18 @@ func (c *client) cmdStatus(ctx context.Context, _ *cli.Command) error {
 
 	_, _ = fmt.Printf("%s", data) //nolint:forbidigo
 
+	// Show the countdown
+	countdown := time.Duration(resp.EventCountdown) * time.Second
+
+	switch {
+	case resp.EventState == api.EventStatePending:
+		_, _ = fmt.Printf("\nThe event starts in %s\n", countdown) //nolint:forbidigo
+	case resp.EventState == api.EventStateRunning && countdown > 0:
+		_, _ = fmt.Printf("\nThe event ends in %s\n", countdown) //nolint:forbidigo
+	case resp.EventState == api.EventStateEnded:
+		_, _ = fmt.Print("\nThe event is over\n") //nolint:forbidigo
+	default:
+	}
+
 	return nil
 }
@@ -69,9 +69,12 @@ func (c *client) cmdSubmit(ctx context.Context, cmd *cli.Command) error {
 
 	// Process the points
 	switch {
-	case resp.Value < 0:
+	case resp.Trap && resp.Value < 0:
 		_, _ = fmt.Printf("You shouldn't have sent that! You just lost your team %d points.\n", resp.Value*-1) //nolint:forbidigo
 
+	case resp.Trap:
+		_, _ = fmt.Print("You shouldn't have sent that!\n") //nolint:forbidigo
+
 	case resp.Value == 0:
 		_, _ = fmt.Print("You sent a valid flag, but no points have been granted.\n") //nolint:forbidigo
 
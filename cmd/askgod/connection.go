@@ -89,6 +89,15 @@ func (c *client) setupClient() error {
 			tlsConfig.RootCAs = caCertPool
 		}
 
+		if c.tlsCert != "" {
+			keypair, err := tls.LoadX509KeyPair(c.tlsCert, c.tlsKey)
+			if err != nil {
+				return fmt.Errorf("failed to load client certificate: %w", err)
+			}
+
+			tlsConfig.Certificates = []tls.Certificate{keypair}
+		}
+
 		transport = &http.Transport{
 			TLSClientConfig:   tlsConfig,
 			DisableKeepAlives: true,
@@ -101,6 +110,9 @@ func (c *client) setupClient() error {
 		Transport: transport,
 	}
 
+	// Tokens are often read from a file
+	c.token = strings.TrimSpace(c.token)
+
 	return nil
 }
 
@@ -138,6 +150,11 @@ func (c *client) queryStruct(ctx context.Context, method string, path string, da
 		}
 	}
 
+	// Identify the team
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
 	// Send the request
 	resp, err := c.http.Do(req)
 	if err != nil {
@@ -188,8 +205,14 @@ func (c *client) websocket(path string) (*websocket.Conn, error) {
 		Proxy:           httpTransport.Proxy,
 	}
 
+	// Identify the team
+	headers := http.Header{}
+	if c.token != "" {
+		headers.Set("Authorization", "Bearer "+c.token)
+	}
+
 	// Establish the connection
-	conn, _, err := dialer.Dial(u, nil) //nolint:bodyclose
+	conn, _, err := dialer.Dial(u, headers) //nolint:bodyclose
 	if err != nil {
 		return nil, err
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/urfave/cli/v3"
 )
@@ -25,6 +26,24 @@ func main() {
 			Usage:       "URL of askgod server",
 			Destination: &c.server,
 		},
+		&cli.StringFlag{
+			Name:        "token",
+			Sources:     cli.NewValueSourceChain(cli.EnvVar("ASKGOD_TOKEN"), cli.File(tokenFile())),
+			Usage:       "API token (team token or admin key, only needed when not connecting from a configured subnet)",
+			Destination: &c.token,
+		},
+		&cli.StringFlag{
+			Name:        "tls-cert",
+			Sources:     cli.EnvVars("ASKGOD_TLS_CERT"),
+			Usage:       "Path to a client certificate to authenticate with",
+			Destination: &c.tlsCert,
+		},
+		&cli.StringFlag{
+			Name:        "tls-key",
+			Sources:     cli.EnvVars("ASKGOD_TLS_KEY"),
+			Usage:       "Path to the key of the client certificate",
+			Destination: &c.tlsKey,
+		},
 	}
 
 	app.Commands = []*cli.Command{
@@ -40,6 +59,64 @@ func main() {
 					Category:  "server",
 					Action:    c.cmdAdminConfig,
 				},
+				{
+					Name:     "audit",
+					Usage:    "List the recorded admin changes",
+					Category: "server",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "principal",
+							Usage: "Only show changes made by the given principal (key:NAME, subnet:IP or peer:IP)",
+						},
+						&cli.StringFlag{
+							Name:  "target",
+							Usage: "Only show changes to URLs starting with the given path (e.g. /1.0/scores)",
+						},
+						&cli.StringFlag{
+							Name:  "since",
+							Usage: "Only show changes made at or after the given time (RFC3339)",
+						},
+						&cli.StringFlag{
+							Name:  "until",
+							Usage: "Only show changes made before the given time (RFC3339)",
+						},
+						&cli.Int64Flag{
+							Name:  "limit",
+							Usage: "Only show the most recent changes",
+						},
+						&cli.BoolFlag{
+							Name:  "changes",
+							Usage: "Show the state before and after each change",
+						},
+					},
+					Action: c.cmdAdminAudit,
+				},
+				{
+					Name:      "add-key",
+					Usage:     "Issue a new admin key (role is one of admin, auditor, flag_manager or score_manager)",
+					ArgsUsage: "<name> <role>",
+					Category:  "server",
+					Action:    c.cmdAdminAddKey,
+				},
+				{
+					Name:      "delete-key",
+					Usage:     "Revoke an admin key",
+					ArgsUsage: "<id>",
+					Category:  "server",
+					Action:    c.cmdAdminDeleteKey,
+				},
+				{
+					Name:     "list-keys",
+					Usage:    "List the admin keys",
+					Category: "server",
+					Action:   c.cmdAdminListKeys,
+				},
+				{
+					Name:     "reveal-scoreboard",
+					Usage:    "Lift the scoreboard freeze and replay the hidden events",
+					Category: "server",
+					Action:   c.cmdAdminRevealScoreboard,
+				},
 				{
 					Name:     "monitor-log",
 					Usage:    "Show live log messages from the server",
@@ -96,6 +173,33 @@ func main() {
 						},
 					},
 				},
+				{
+					Name:      "generate-flag-variants",
+					Usage:     "Generate a unique variant of a flag for every team",
+					ArgsUsage: "<id>",
+					Category:  "flags",
+					Action:    c.cmdAdminGenerateFlagVariants,
+				},
+				{
+					Name:      "import-flag-variants",
+					Usage:     "Import a list of team variants for a flag",
+					ArgsUsage: "<id> <filename>",
+					Category:  "flags",
+					Action:    c.cmdAdminImportFlagVariants,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "flush",
+							Usage: "Remove all existings variants of the flag",
+						},
+					},
+				},
+				{
+					Name:      "list-flag-variants",
+					Usage:     "List the team variants of a flag",
+					ArgsUsage: "<id>",
+					Category:  "flags",
+					Action:    c.cmdAdminListFlagVariants,
+				},
 				{
 					Name:     "list-flags",
 					Usage:    "List all the flags",
@@ -107,7 +211,13 @@ func main() {
 					Usage:     "Update a flag",
 					ArgsUsage: "<id> [key=value...]",
 					Category:  "flags",
-					Action:    c.cmdAdminUpdateFlag,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "propagate",
+							Usage: "Also update the value of the existing scores for the flag",
+						},
+					},
+					Action: c.cmdAdminUpdateFlag,
 				},
 
 				{
@@ -136,12 +246,33 @@ func main() {
 						},
 					},
 				},
+				{
+					Name:      "issue-team-token",
+					Usage:     "Issue a new API token for a team",
+					ArgsUsage: "<team id> [description]",
+					Category:  "teams",
+					Action:    c.cmdAdminIssueTeamToken,
+				},
+				{
+					Name:      "list-team-tokens",
+					Usage:     "List the API tokens of a team",
+					ArgsUsage: "<team id>",
+					Category:  "teams",
+					Action:    c.cmdAdminListTeamTokens,
+				},
 				{
 					Name:     "list-teams",
 					Usage:    "List all the teams",
 					Category: "teams",
 					Action:   c.cmdAdminListTeams,
 				},
+				{
+					Name:      "revoke-team-token",
+					Usage:     "Revoke an API token of a team",
+					ArgsUsage: "<team id> <token id>",
+					Category:  "teams",
+					Action:    c.cmdAdminRevokeTeamToken,
+				},
 				{
 					Name:      "update-team",
 					Usage:     "Update a team",
@@ -150,6 +281,63 @@ func main() {
 					Action:    c.cmdAdminUpdateTeam,
 				},
 
+				{
+					Name:      "add-hint",
+					Usage:     "Add a new hint",
+					ArgsUsage: "[key=value...]",
+					Category:  "hints",
+					Action:    c.cmdAdminAddHint,
+				},
+				{
+					Name:     "delete-hint",
+					Usage:    "Delete a hint",
+					Category: "hints",
+					Action:   c.cmdAdminDeleteHint,
+				},
+				{
+					Name:     "list-hints",
+					Usage:    "List all the hints",
+					Category: "hints",
+					Action:   c.cmdAdminListHints,
+				},
+				{
+					Name:      "update-hint",
+					Usage:     "Update a hint",
+					ArgsUsage: "<id> [key=value...]",
+					Category:  "hints",
+					Action:    c.cmdAdminUpdateHint,
+				},
+
+				{
+					Name:     "adjust",
+					Usage:    "Manage manual score adjustments (bonuses and penalties)",
+					Category: "scores",
+					Commands: []*cli.Command{
+						{
+							Name:      "add",
+							Usage:     "Adjust a team's score",
+							ArgsUsage: "<team id> <value> <reason>",
+							Action:    c.cmdAdminAdjustAdd,
+						},
+						{
+							Name:      "delete",
+							Usage:     "Delete a score adjustment",
+							ArgsUsage: "<id>",
+							Action:    c.cmdAdminAdjustDelete,
+						},
+						{
+							Name:   "list",
+							Usage:  "List all the score adjustments",
+							Action: c.cmdAdminAdjustList,
+						},
+						{
+							Name:      "update",
+							Usage:     "Update a score adjustment",
+							ArgsUsage: "<id> [key=value...]",
+							Action:    c.cmdAdminAdjustUpdate,
+						},
+					},
+				},
 				{
 					Name:      "add-score",
 					Usage:     "Add a new score entry",
@@ -207,6 +395,52 @@ func main() {
 					},
 					Action: c.cmdAdminHistory,
 				},
+				{
+					Name:     "near-misses",
+					Usage:    "Show the flags with the most near-miss submissions",
+					Category: "scores",
+					Action:   c.cmdAdminNearMisses,
+				},
+				{
+					Name:     "submissions",
+					Usage:    "List the flag submission attempts",
+					Category: "scores",
+					Flags: []cli.Flag{
+						&cli.Int64Flag{
+							Name:  "team",
+							Usage: "Only show attempts from the given team ID",
+						},
+						&cli.Int64Flag{
+							Name:  "flag",
+							Usage: "Only show attempts matching the given flag ID",
+						},
+						&cli.StringFlag{
+							Name:  "ip",
+							Usage: "Only show attempts from the given IP address",
+						},
+						&cli.StringFlag{
+							Name:  "outcome",
+							Usage: "Only show attempts with the given outcome (valid, invalid, duplicate, ...)",
+						},
+						&cli.StringFlag{
+							Name:  "source",
+							Usage: "Only show attempts from the given source",
+						},
+						&cli.StringFlag{
+							Name:  "since",
+							Usage: "Only show attempts made at or after the given time (RFC3339)",
+						},
+						&cli.StringFlag{
+							Name:  "until",
+							Usage: "Only show attempts made before the given time (RFC3339)",
+						},
+						&cli.Int64Flag{
+							Name:  "limit",
+							Usage: "Only show the most recent attempts",
+						},
+					},
+					Action: c.cmdAdminSubmissions,
+				},
 				{
 					Name:     "stats",
 					Usage:    "Show per-flag solve statistics",
@@ -223,6 +457,24 @@ func main() {
 			Action:    c.cmdDetails,
 		},
 
+		{
+			Name:  "hint",
+			Usage: "List and unlock hints",
+			Commands: []*cli.Command{
+				{
+					Name:   "list",
+					Usage:  "List all the hints",
+					Action: c.cmdHintList,
+				},
+				{
+					Name:      "unlock",
+					Usage:     "Unlock a hint (its cost is taken from your score)",
+					ArgsUsage: "<id>",
+					Action:    c.cmdHintUnlock,
+				},
+			},
+		},
+
 		{
 			Name:      "history",
 			Usage:     "List all submitted flags",
@@ -245,6 +497,18 @@ func main() {
 					Name:  "live",
 					Usage: "Keep updating the scoreboard as it changes",
 				},
+				&cli.StringFlag{
+					Name:  "tag",
+					Usage: "Only count the flags with the given tag (key:value)",
+				},
+				&cli.StringFlag{
+					Name:  "division",
+					Usage: "Only show the teams in the given division",
+				},
+				&cli.StringFlag{
+					Name:  "at",
+					Usage: "Show the scoreboard as it was at the given time (RFC3339)",
+				},
 			},
 			Action: c.cmdScoreboard,
 		},
@@ -284,3 +548,13 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// tokenFile returns the path of the file the team token is read from.
+func tokenFile() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(configDir, "askgod", "token")
+}
@@ -5,6 +5,9 @@ import (
 )
 
 type client struct {
-	http   *http.Client
-	server string
+	http    *http.Client
+	server  string
+	token   string
+	tlsCert string
+	tlsKey  string
 }
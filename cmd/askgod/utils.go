@@ -6,6 +6,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nsec/askgod/api"
 	"github.com/nsec/askgod/internal/utils"
@@ -111,6 +112,39 @@ func setStructKey(obj any, arg string) error {
 
 		field.SetInt(intValue)
 
+	case field.Type() == reflect.TypeFor[time.Time]():
+		timeValue := time.Time{}
+
+		if fields[1] != "" {
+			var err error
+
+			timeValue, err = time.Parse(time.RFC3339, fields[1])
+			if err != nil {
+				return err
+			}
+		}
+
+		field.Set(reflect.ValueOf(timeValue))
+
+	case field.Type() == reflect.TypeFor[[]int64]():
+		values := []int64{}
+
+		for entry := range strings.SplitSeq(fields[1], ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+
+			intValue, err := strconv.ParseInt(entry, 10, 64)
+			if err != nil {
+				return err
+			}
+
+			values = append(values, intValue)
+		}
+
+		field.Set(reflect.ValueOf(values))
+
 	case field.Type() == reflect.TypeFor[map[string]string]():
 		tags, err := utils.ParseTags(fields[1])
 		if err != nil {
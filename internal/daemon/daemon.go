@@ -3,6 +3,7 @@ package daemon
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
@@ -10,13 +11,14 @@ import (
 	"net"
 	"net/http"
 	"os"
-	"strings"
+	"slices"
 	"time"
 
 	"github.com/armon/go-proxyproto"
 	"github.com/inconshreveable/log15"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"github.com/nsec/askgod/api"
 	"github.com/nsec/askgod/internal/config"
 	"github.com/nsec/askgod/internal/database"
 	"github.com/nsec/askgod/internal/rest"
@@ -129,7 +131,7 @@ func (d *Daemon) Run(ctx context.Context) error {
 	// Setup the REST API
 	d.router = http.NewServeMux()
 
-	err = rest.AttachFunctions(
+	isTrustedProxy, err := rest.AttachFunctions(
 		ctx,
 		d.config,
 		d.router,
@@ -139,6 +141,22 @@ func (d *Daemon) Run(ctx context.Context) error {
 		return err
 	}
 
+	if !slices.Contains([]string{"", "X-Forwarded-For", "Forwarded"}, http.CanonicalHeaderKey(d.config.Daemon.ForwardedHeader)) {
+		return fmt.Errorf("invalid forwarded header %q", d.config.Daemon.ForwardedHeader)
+	}
+
+	// Check the certificate presented to cluster peers
+	if d.config.Daemon.ClusterCertificate != "" || d.config.Daemon.ClusterKey != "" {
+		_, err := utils.ReadKeyPair(d.config.Daemon.ClusterCertificate, d.config.Daemon.ClusterKey)
+		if err != nil {
+			return fmt.Errorf("invalid cluster certificate: %w", err)
+		}
+	}
+
+	if d.config.Daemon.HAProxyHeader && len(d.config.Daemon.TrustedProxies) == 0 {
+		d.logger.Warn("Accepting PROXY protocol headers from any source, consider setting daemon.trusted_proxies")
+	}
+
 	// HTTP
 	chServers := make(chan error, 1)
 
@@ -153,7 +171,7 @@ func (d *Daemon) Run(ctx context.Context) error {
 
 		// Wrap for HAProxy
 		if d.config.Daemon.HAProxyHeader {
-			socket = &proxyproto.Listener{Listener: socket}
+			socket = &proxyproto.Listener{Listener: socket, SourceCheck: d.proxySourceCheck(isTrustedProxy)}
 		}
 
 		d.logger.Info("Binding HTTP", log15.Ctx{"port": d.config.Daemon.HTTPPort})
@@ -177,35 +195,46 @@ func (d *Daemon) Run(ctx context.Context) error {
 
 	if d.config.Daemon.HTTPSPort > 0 {
 		// Load the X509 certificates
-		cert := d.config.Daemon.HTTPSCertificate
-		if !strings.Contains(cert, "\n") && utils.PathExists(cert) {
-			content, err := os.ReadFile(cert)
-			if err != nil {
-				return err
-			}
+		keypair, err := utils.ReadKeyPair(d.config.Daemon.HTTPSCertificate, d.config.Daemon.HTTPSKey)
+		if err != nil {
+			return err
+		}
 
-			cert = string(content)
+		// Setup a strict TLS config
+		tlsConfig := &tls.Config{
+			Certificates: []tls.Certificate{keypair},
+			MinVersion:   tls.VersionTLS13,
 		}
 
-		key := d.config.Daemon.HTTPSKey
-		if !strings.Contains(key, "\n") && utils.PathExists(key) {
-			content, err := os.ReadFile(key)
+		// Verify client certificates
+		if d.config.Daemon.HTTPSClientCA != "" {
+			clientCA, err := utils.ReadPEM(d.config.Daemon.HTTPSClientCA)
 			if err != nil {
 				return err
 			}
 
-			key = string(content)
-		}
+			clientCAs := x509.NewCertPool()
+			if !clientCAs.AppendCertsFromPEM([]byte(clientCA)) {
+				return errors.New("failed to parse the client CA certificate")
+			}
 
-		x509, err := tls.X509KeyPair([]byte(cert), []byte(key))
-		if err != nil {
-			return err
-		}
+			tlsConfig.ClientCAs = clientCAs
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
 
-		// Setup a strict TLS config
-		tlsConfig := &tls.Config{
-			Certificates: []tls.Certificate{x509},
-			MinVersion:   tls.VersionTLS13,
+			// Validate the certificate mappings
+			for _, entry := range d.config.Daemon.ClientCertificates {
+				if entry.Name == "" {
+					return errors.New("client certificate mappings require a name")
+				}
+
+				if !slices.Contains([]string{api.AdminRoleAdmin, api.AdminRoleAuditor, api.AdminRoleFlagManager, api.AdminRoleScoreManager, api.ClientCertificateRoleTeam, api.ClientCertificateRolePeer}, entry.Role) {
+					return fmt.Errorf("invalid role for client certificate %q: %s", entry.Name, entry.Role)
+				}
+
+				if entry.Role == api.ClientCertificateRoleTeam && entry.TeamID <= 0 {
+					return fmt.Errorf("missing team ID for client certificate %q", entry.Name)
+				}
+			}
 		}
 
 		// Prepare the TCP socket
@@ -218,7 +247,7 @@ func (d *Daemon) Run(ctx context.Context) error {
 
 		// Wrap for HAProxy
 		if d.config.Daemon.HAProxyHeader {
-			socket = &proxyproto.Listener{Listener: socket}
+			socket = &proxyproto.Listener{Listener: socket, SourceCheck: d.proxySourceCheck(isTrustedProxy)}
 		}
 
 		// Wrap for TLS
@@ -281,3 +310,22 @@ func (d *Daemon) Run(ctx context.Context) error {
 
 	return nil
 }
+
+// proxySourceCheck only honours PROXY protocol headers from the trusted proxies (if any are configured).
+// Other connections are still accepted, using their own address.
+// The trusted proxies are those of the REST API, following configuration changes.
+func (d *Daemon) proxySourceCheck(isTrustedProxy func(net.IP) bool) proxyproto.SourceChecker {
+	return func(addr net.Addr) (bool, error) {
+		if len(d.config.Daemon.TrustedProxies) == 0 {
+			return true, nil
+		}
+
+		tcpAddr, ok := addr.(*net.TCPAddr)
+		if ok && isTrustedProxy(tcpAddr.IP) {
+			return true, nil
+		}
+
+		// Keep the connection address, ignoring any PROXY header
+		return false, nil
+	}
+}
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/nsec/askgod/api"
+)
+
+// scoreEntriesValued is a SQL table expression combining the score entries (valued by the given SQL expression) and the manual adjustments.
+const scoreEntriesValued = "(SELECT id, teamid, flagid, %s AS value, submit_time, type FROM score UNION ALL SELECT id, teamid, NULL, value, submit_time, 'adjustment' FROM adjustment) AS score"
+
+// scoreEntries is a SQL table expression combining the score entries and the manual adjustments.
+var scoreEntries = fmt.Sprintf(scoreEntriesValued, "value")
+
+// GetAdjustments retrieves all the score adjustments from the database.
+func (db *DB) GetAdjustments(ctx context.Context) ([]api.AdminAdjustment, error) {
+	// Return a list of adjustments
+	resp := []api.AdminAdjustment{}
+
+	// Query all the adjustments from the database
+	rows, err := db.QueryContext(ctx, "SELECT id, teamid, value, reason, author, submit_time FROM adjustment ORDER BY id ASC;")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Iterate through the results
+	for rows.Next() {
+		row := api.AdminAdjustment{}
+
+		err := rows.Scan(&row.ID, &row.TeamID, &row.Value, &row.Reason, &row.Author, &row.SubmitTime)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = append(resp, row)
+	}
+
+	// Check for any error that might have happened
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// GetAdjustment retrieves a single score adjustment from the database.
+func (db *DB) GetAdjustment(ctx context.Context, id int64) (*api.AdminAdjustment, error) {
+	// Query the database entry
+	row := api.AdminAdjustment{}
+
+	err := db.QueryRowContext(ctx, "SELECT id, teamid, value, reason, author, submit_time FROM adjustment WHERE id=$1;", id).Scan(
+		&row.ID, &row.TeamID, &row.Value, &row.Reason, &row.Author, &row.SubmitTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return &row, nil
+}
+
+// CreateAdjustment adds a new score adjustment to the database, attributed to the given author.
+func (db *DB) CreateAdjustment(ctx context.Context, adjustment api.AdminAdjustmentPost, author string) (int64, error) {
+	id := int64(-1)
+
+	// Create the database entry
+	err := db.QueryRowContext(ctx, "INSERT INTO adjustment (teamid, value, reason, author, submit_time) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+		adjustment.TeamID, adjustment.Value, adjustment.Reason, author, time.Now()).Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// UpdateAdjustment updates an existing score adjustment.
+func (db *DB) UpdateAdjustment(ctx context.Context, id int64, adjustment api.AdminAdjustmentPut) error {
+	// Update the database entry
+	result, err := db.ExecContext(ctx, "UPDATE adjustment SET value=$1, reason=$2 WHERE id=$3;",
+		adjustment.Value, adjustment.Reason, id)
+	if err != nil {
+		return err
+	}
+
+	// Check that a change indeed happened
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteAdjustment deletes a single score adjustment from the database.
+func (db *DB) DeleteAdjustment(ctx context.Context, id int64) error {
+	// Delete the database entry
+	result, err := db.ExecContext(ctx, "DELETE FROM adjustment WHERE id=$1;", id)
+	if err != nil {
+		return err
+	}
+
+	// Check that a change indeed happened
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ClearAdjustments wipes all score adjustments from the database.
+func (db *DB) ClearAdjustments(ctx context.Context) error {
+	// Start a transaction
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// Wipe the table
+	_, err = tx.ExecContext(ctx, "DELETE FROM adjustment;")
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+
+		return err
+	}
+
+	// Reset the sequence
+	_, err = tx.ExecContext(ctx, "ALTER SEQUENCE adjustment_id_seq RESTART;")
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+
+		return err
+	}
+
+	// Commit
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/nsec/askgod/api"
+)
+
+// GetAuditEntries retrieves the audit entries matching the filter, oldest first.
+func (db *DB) GetAuditEntries(ctx context.Context, filter AuditFilter) ([]api.AdminAuditEntry, error) {
+	// Return a list of entries
+	resp := []api.AdminAuditEntry{}
+
+	// Query the most recent matching entries from the database
+	rows, err := db.QueryContext(ctx, "SELECT id, principal, ip, method, url, status, before, after, submit_time FROM audit WHERE ($1 = '' OR principal=$1) AND ($2 = '' OR starts_with(url, $2)) AND ($3::TIMESTAMPTZ IS NULL OR submit_time >= $3) AND ($4::TIMESTAMPTZ IS NULL OR submit_time < $4) ORDER BY id DESC LIMIT NULLIF($5, 0);",
+		filter.Principal, filter.Target, nullTime(filter.Since), nullTime(filter.Until), filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Iterate through the results
+	for rows.Next() {
+		row := api.AdminAuditEntry{}
+
+		var before, after []byte
+
+		err := rows.Scan(&row.ID, &row.Principal, &row.IP, &row.Method, &row.URL, &row.Status, &before, &after, &row.SubmitTime)
+		if err != nil {
+			return nil, err
+		}
+
+		row.Before = before
+		row.After = after
+
+		resp = append(resp, row)
+	}
+
+	// Check for any error that might have happened
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(resp)
+
+	return resp, nil
+}
+
+// CreateAuditEntry appends a new entry to the audit log.
+func (db *DB) CreateAuditEntry(ctx context.Context, entry api.AdminAuditEntry) (int64, error) {
+	id := int64(-1)
+
+	// Create the database entry
+	err := db.QueryRowContext(ctx, "INSERT INTO audit (principal, ip, method, url, status, before, after, submit_time) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id",
+		entry.Principal, entry.IP, entry.Method, entry.URL, entry.Status, nullJSON(entry.Before), nullJSON(entry.After), time.Now()).Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// nullJSON converts a JSON value to its SQL representation (NULL when missing).
+func nullJSON(value json.RawMessage) any {
+	if len(value) == 0 {
+		return nil
+	}
+
+	return string(value)
+}
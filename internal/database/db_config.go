@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nsec/askgod/api"
 )
@@ -42,13 +43,55 @@ func (db *DB) GetConfig(ctx context.Context) (*api.ConfigPut, error) {
 		return nil, ErrEmptyConfig
 	}
 
+	// Parse the non-string values
+	firstBloodBonus, err := parseInt64List(dbConfig["scoring.first_blood_bonus"])
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimit := map[string]int64{}
+
+	for _, key := range []string{"scoring.rate_limit_team", "scoring.rate_limit_ip", "scoring.rate_limit_window", "scoring.rate_limit_cooldown"} {
+		if dbConfig[key] == "" {
+			continue
+		}
+
+		rateLimit[key], err = strconv.ParseInt(dbConfig[key], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	times := map[string]time.Time{}
+
+	for _, key := range []string{"scoring.freeze_at", "scoring.start_time", "scoring.end_time"} {
+		if dbConfig[key] == "" {
+			continue
+		}
+
+		times[key], err = time.Parse(time.RFC3339, dbConfig[key])
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Apply mapping
 	resp := api.ConfigPut{
 		Scoring: api.ConfigScoring{
-			EventName:  dbConfig["scoring.event_name"],
-			HideOthers: dbConfig["scoring.hide_others"] == "true",
-			ReadOnly:   dbConfig["scoring.read_only"] == "true",
-			PublicTags: strings.Split(dbConfig["scoring.public_tags"], ","),
+			EventName:         dbConfig["scoring.event_name"],
+			HideOthers:        dbConfig["scoring.hide_others"] == "true",
+			ReadOnly:          dbConfig["scoring.read_only"] == "true",
+			PublicTags:        strings.Split(dbConfig["scoring.public_tags"], ","),
+			Mode:              dbConfig["scoring.mode"],
+			FirstBloodBonus:   firstBloodBonus,
+			RateLimitTeam:     rateLimit["scoring.rate_limit_team"],
+			RateLimitIP:       rateLimit["scoring.rate_limit_ip"],
+			RateLimitWindow:   rateLimit["scoring.rate_limit_window"],
+			RateLimitCooldown: rateLimit["scoring.rate_limit_cooldown"],
+			FreezeAt:          times["scoring.freeze_at"],
+			StartTime:         times["scoring.start_time"],
+			EndTime:           times["scoring.end_time"],
+			Tiebreak:          dbConfig["scoring.tiebreak"],
 		},
 		Teams: api.ConfigTeams{
 			SelfRegister: dbConfig["teams.self_register"] == "true",
@@ -92,16 +135,26 @@ func (db *DB) UpdateConfig(ctx context.Context, config api.ConfigPut) error {
 
 	// Setup mapping
 	dbConfig := map[string]string{
-		"scoring.event_name":  config.Scoring.EventName,
-		"scoring.hide_others": strconv.FormatBool(config.Scoring.HideOthers),
-		"scoring.read_only":   strconv.FormatBool(config.Scoring.ReadOnly),
-		"scoring.public_tags": strings.Join(config.Scoring.PublicTags, ","),
-		"teams.self_register": strconv.FormatBool(config.Teams.SelfRegister),
-		"teams.self_update":   strconv.FormatBool(config.Teams.SelfUpdate),
-		"teams.hidden":        strings.Join(config.Teams.Hidden, ","),
-		"subnets.admins":      strings.Join(config.Subnets.Admins, ","),
-		"subnets.teams":       strings.Join(config.Subnets.Teams, ","),
-		"subnets.guests":      strings.Join(config.Subnets.Guests, ","),
+		"scoring.event_name":          config.Scoring.EventName,
+		"scoring.hide_others":         strconv.FormatBool(config.Scoring.HideOthers),
+		"scoring.read_only":           strconv.FormatBool(config.Scoring.ReadOnly),
+		"scoring.public_tags":         strings.Join(config.Scoring.PublicTags, ","),
+		"scoring.mode":                config.Scoring.Mode,
+		"scoring.first_blood_bonus":   packInt64List(config.Scoring.FirstBloodBonus),
+		"scoring.rate_limit_team":     strconv.FormatInt(config.Scoring.RateLimitTeam, 10),
+		"scoring.rate_limit_ip":       strconv.FormatInt(config.Scoring.RateLimitIP, 10),
+		"scoring.rate_limit_window":   strconv.FormatInt(config.Scoring.RateLimitWindow, 10),
+		"scoring.rate_limit_cooldown": strconv.FormatInt(config.Scoring.RateLimitCooldown, 10),
+		"scoring.freeze_at":           formatTime(config.Scoring.FreezeAt),
+		"scoring.start_time":          formatTime(config.Scoring.StartTime),
+		"scoring.end_time":            formatTime(config.Scoring.EndTime),
+		"scoring.tiebreak":            config.Scoring.Tiebreak,
+		"teams.self_register":         strconv.FormatBool(config.Teams.SelfRegister),
+		"teams.self_update":           strconv.FormatBool(config.Teams.SelfUpdate),
+		"teams.hidden":                strings.Join(config.Teams.Hidden, ","),
+		"subnets.admins":              strings.Join(config.Subnets.Admins, ","),
+		"subnets.teams":               strings.Join(config.Subnets.Teams, ","),
+		"subnets.guests":              strings.Join(config.Subnets.Guests, ","),
 	}
 
 	// Insert the new config
@@ -125,3 +178,41 @@ func (db *DB) UpdateConfig(ctx context.Context, config api.ConfigPut) error {
 
 	return nil
 }
+
+func parseInt64List(in string) ([]int64, error) {
+	out := []int64{}
+
+	for entry := range strings.SplitSeq(in, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		value, err := strconv.ParseInt(entry, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, value)
+	}
+
+	return out, nil
+}
+
+func packInt64List(in []int64) string {
+	out := make([]string, 0, len(in))
+
+	for _, value := range in {
+		out = append(out, strconv.FormatInt(value, 10))
+	}
+
+	return strings.Join(out, ",")
+}
+
+func formatTime(in time.Time) string {
+	if in.IsZero() {
+		return ""
+	}
+
+	return in.Format(time.RFC3339)
+}
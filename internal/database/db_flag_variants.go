@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/nsec/askgod/api"
+)
+
+// ErrFlagVariantConflict indicates that a flag variant matches an existing flag or variant (ignoring case).
+var ErrFlagVariantConflict = errors.New("flag variant conflicts with an existing flag")
+
+// GetFlagVariants retrieves all the variants of a flag from the database.
+func (db *DB) GetFlagVariants(ctx context.Context, flagid int64) ([]api.AdminFlagVariant, error) {
+	// Return a list of variants
+	resp := []api.AdminFlagVariant{}
+
+	// Query all the variants from the database
+	rows, err := db.QueryContext(ctx, "SELECT id, flagid, teamid, flag FROM flag_variant WHERE flagid=$1 ORDER BY teamid ASC;", flagid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Iterate through the results
+	for rows.Next() {
+		row := api.AdminFlagVariant{}
+
+		err := rows.Scan(&row.ID, &row.FlagID, &row.TeamID, &row.Flag)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = append(resp, row)
+	}
+
+	// Check for any error that might have happened
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// CreateFlagVariant adds a new team variant of a flag to the database.
+func (db *DB) CreateFlagVariant(ctx context.Context, flagid int64, variant api.AdminFlagVariantPost) (int64, error) {
+	// Create the database entry unless the variant matches a flag or another variant
+	id := int64(-1)
+
+	err := db.QueryRowContext(ctx, "INSERT INTO flag_variant (flagid, teamid, flag) SELECT $1::INTEGER, $2::INTEGER, $3::VARCHAR WHERE NOT EXISTS (SELECT 1 FROM flag WHERE LOWER(flag)=LOWER($3)) AND NOT EXISTS (SELECT 1 FROM flag_variant WHERE LOWER(flag)=LOWER($3)) RETURNING id",
+		flagid, variant.TeamID, variant.Flag).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return -1, ErrFlagVariantConflict
+	} else if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// ClearFlagVariants wipes all the variants of a flag from the database.
+func (db *DB) ClearFlagVariants(ctx context.Context, flagid int64) error {
+	_, err := db.ExecContext(ctx, "DELETE FROM flag_variant WHERE flagid=$1;", flagid)
+
+	return err
+}
@@ -3,18 +3,23 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"time"
 
 	"github.com/nsec/askgod/api"
 	"github.com/nsec/askgod/internal/utils"
 )
 
+// ErrFlagConflict indicates that a flag matches the team variant of a flag (ignoring case).
+var ErrFlagConflict = errors.New("flag conflicts with an existing flag variant")
+
 // GetFlags retrieves all the flag entries from the database.
 func (db *DB) GetFlags(ctx context.Context) ([]api.AdminFlag, error) {
 	// Return a list of flags
 	resp := []api.AdminFlag{}
 
 	// Query all the flags from the database
-	rows, err := db.QueryContext(ctx, "SELECT id, flag, value, return_string, description, tags FROM flag ORDER BY id ASC;")
+	rows, err := db.QueryContext(ctx, "SELECT id, flag, value, return_string, description, tags, initial_value, minimum_value, decay, requires, valid_from, valid_until, trap FROM flag ORDER BY id ASC;")
 	if err != nil {
 		return nil, err
 	}
@@ -24,8 +29,11 @@ func (db *DB) GetFlags(ctx context.Context) ([]api.AdminFlag, error) {
 	for rows.Next() {
 		row := api.AdminFlag{}
 		tags := ""
+		requires := ""
+		validFrom := sql.NullTime{}
+		validUntil := sql.NullTime{}
 
-		err := rows.Scan(&row.ID, &row.Flag, &row.Value, &row.ReturnString, &row.Description, &tags)
+		err := rows.Scan(&row.ID, &row.Flag, &row.Value, &row.ReturnString, &row.Description, &tags, &row.InitialValue, &row.MinimumValue, &row.Decay, &requires, &validFrom, &validUntil, &row.Trap)
 		if err != nil {
 			return nil, err
 		}
@@ -35,6 +43,14 @@ func (db *DB) GetFlags(ctx context.Context) ([]api.AdminFlag, error) {
 			return nil, err
 		}
 
+		row.Requires, err = parseInt64List(requires)
+		if err != nil {
+			return nil, err
+		}
+
+		row.ValidFrom = validFrom.Time
+		row.ValidUntil = validUntil.Time
+
 		resp = append(resp, row)
 	}
 
@@ -52,9 +68,12 @@ func (db *DB) GetFlag(ctx context.Context, id int64) (*api.AdminFlag, error) {
 	// Query the database entry
 	row := api.AdminFlag{}
 	tags := ""
+	requires := ""
+	validFrom := sql.NullTime{}
+	validUntil := sql.NullTime{}
 
-	err := db.QueryRowContext(ctx, "SELECT id, flag, value, return_string, description, tags FROM flag WHERE id=$1;", id).Scan(
-		&row.ID, &row.Flag, &row.Value, &row.ReturnString, &row.Description, &tags)
+	err := db.QueryRowContext(ctx, "SELECT id, flag, value, return_string, description, tags, initial_value, minimum_value, decay, requires, valid_from, valid_until, trap FROM flag WHERE id=$1;", id).Scan(
+		&row.ID, &row.Flag, &row.Value, &row.ReturnString, &row.Description, &tags, &row.InitialValue, &row.MinimumValue, &row.Decay, &requires, &validFrom, &validUntil, &row.Trap)
 	if err != nil {
 		return nil, err
 	}
@@ -64,28 +83,136 @@ func (db *DB) GetFlag(ctx context.Context, id int64) (*api.AdminFlag, error) {
 		return nil, err
 	}
 
+	row.Requires, err = parseInt64List(requires)
+	if err != nil {
+		return nil, err
+	}
+
+	row.ValidFrom = validFrom.Time
+	row.ValidUntil = validUntil.Time
+
 	return &row, nil
 }
 
+// flagInsertQuery is the SQL statement used to create a flag, unless it matches a team variant.
+const flagInsertQuery = "INSERT INTO flag (flag, value, return_string, description, tags, initial_value, minimum_value, decay, requires, valid_from, valid_until, trap) SELECT $1::VARCHAR, $2::INTEGER, $3::VARCHAR, $4::VARCHAR, $5::VARCHAR, $6::INTEGER, $7::INTEGER, $8::INTEGER, $9::VARCHAR, $10::TIMESTAMPTZ, $11::TIMESTAMPTZ, $12::BOOLEAN WHERE NOT EXISTS (SELECT 1 FROM flag_variant WHERE LOWER(flag_variant.flag)=LOWER($1)) RETURNING id"
+
 // CreateFlag adds a new flag to the database.
 func (db *DB) CreateFlag(ctx context.Context, flag api.AdminFlagPost) (int64, error) {
 	id := int64(-1)
 
 	// Create the database entry
-	err := db.QueryRowContext(ctx, "INSERT INTO flag (flag, value, return_string, description, tags) VALUES ($1, $2, $3, $4, $5) RETURNING id",
-		flag.Flag, flag.Value, flag.ReturnString, flag.Description, utils.PackTags(flag.Tags)).Scan(&id)
-	if err != nil {
+	err := db.QueryRowContext(ctx, flagInsertQuery,
+		flag.Flag, flag.Value, flag.ReturnString, flag.Description, utils.PackTags(flag.Tags), flag.InitialValue, flag.MinimumValue, flag.Decay, packInt64List(flag.Requires), nullTime(flag.ValidFrom), nullTime(flag.ValidUntil), flag.Trap).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return -1, ErrFlagConflict
+	} else if err != nil {
 		return -1, err
 	}
 
 	return id, nil
 }
 
+// ImportFlags adds a set of flags to the database in a single transaction.
+// The flag IDs are only used to resolve prerequisites between the imported flags,
+// the IDs of the new flags are returned in the same order.
+func (db *DB) ImportFlags(ctx context.Context, flags []api.AdminFlag) ([]int64, error) {
+	// Start a transaction
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the flags without their prerequisites
+	resp := make([]int64, 0, len(flags))
+	ids := map[int64]int64{}
+
+	for _, flag := range flags {
+		id := int64(-1)
+
+		err := tx.QueryRowContext(ctx, flagInsertQuery,
+			flag.Flag, flag.Value, flag.ReturnString, flag.Description, utils.PackTags(flag.Tags), flag.InitialValue, flag.MinimumValue, flag.Decay, packInt64List(nil), nullTime(flag.ValidFrom), nullTime(flag.ValidUntil), flag.Trap).Scan(&id)
+		if err != nil {
+			errRollback := tx.Rollback()
+			if errRollback != nil {
+				return nil, errRollback
+			}
+
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrFlagConflict
+			}
+
+			return nil, err
+		}
+
+		if flag.ID > 0 {
+			ids[flag.ID] = id
+		}
+
+		resp = append(resp, id)
+	}
+
+	// Set the prerequisites using the new IDs
+	for i, flag := range flags {
+		if len(flag.Requires) == 0 {
+			continue
+		}
+
+		requires := make([]int64, 0, len(flag.Requires))
+
+		for _, required := range flag.Requires {
+			newID, ok := ids[required]
+			if ok {
+				required = newID
+			}
+
+			requires = append(requires, required)
+		}
+
+		_, err := tx.ExecContext(ctx, "UPDATE flag SET requires=$1 WHERE id=$2;", packInt64List(requires), resp[i])
+		if err != nil {
+			errRollback := tx.Rollback()
+			if errRollback != nil {
+				return nil, errRollback
+			}
+
+			return nil, err
+		}
+	}
+
+	// Commit
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// flagUpdateQuery is the SQL statement used to update all the editable fields of a flag, unless it matches a team variant.
+const flagUpdateQuery = "UPDATE flag SET flag=$1, value=$2, return_string=$3, description=$4, tags=$5, initial_value=$6, minimum_value=$7, decay=$8, requires=$9, valid_from=$10, valid_until=$11, trap=$12 WHERE id=$13 AND NOT EXISTS (SELECT 1 FROM flag_variant WHERE LOWER(flag_variant.flag)=LOWER($1));"
+
+// flagUpdateError figures out why updating a flag didn't change any row.
+func (db *DB) flagUpdateError(ctx context.Context, id int64) error {
+	exists := false
+
+	err := db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM flag WHERE id=$1);", id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	return ErrFlagConflict
+}
+
 // UpdateFlag updates an existing flag.
 func (db *DB) UpdateFlag(ctx context.Context, id int64, flag api.AdminFlagPut) error {
 	// Update the database entry
-	result, err := db.ExecContext(ctx, "UPDATE flag SET flag=$1, value=$2, return_string=$3, description=$4, tags=$5 WHERE id=$6;",
-		flag.Flag, flag.Value, flag.ReturnString, flag.Description, utils.PackTags(flag.Tags), id)
+	result, err := db.ExecContext(ctx, flagUpdateQuery,
+		flag.Flag, flag.Value, flag.ReturnString, flag.Description, utils.PackTags(flag.Tags), flag.InitialValue, flag.MinimumValue, flag.Decay, packInt64List(flag.Requires), nullTime(flag.ValidFrom), nullTime(flag.ValidUntil), flag.Trap, id)
 	if err != nil {
 		return err
 	}
@@ -97,12 +224,73 @@ func (db *DB) UpdateFlag(ctx context.Context, id int64, flag api.AdminFlagPut) e
 	}
 
 	if count == 0 {
-		return sql.ErrNoRows
+		return db.flagUpdateError(ctx, id)
 	}
 
 	return nil
 }
 
+// UpdateFlagPropagate updates an existing flag and rewrites the value of all its existing solves to match.
+// It returns the change in points for every team whose score entry was modified.
+func (db *DB) UpdateFlagPropagate(ctx context.Context, id int64, flag api.AdminFlagPut, scoring api.ConfigScoring) (map[int64]int64, error) {
+	// Start a transaction
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update the flag itself
+	result, err := tx.ExecContext(ctx, flagUpdateQuery,
+		flag.Flag, flag.Value, flag.ReturnString, flag.Description, utils.PackTags(flag.Tags), flag.InitialValue, flag.MinimumValue, flag.Decay, packInt64List(flag.Requires), nullTime(flag.ValidFrom), nullTime(flag.ValidUntil), flag.Trap, id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return nil, errRollback
+		}
+
+		return nil, err
+	}
+
+	// Check that a change indeed happened
+	count, err := result.RowsAffected()
+	if err != nil || count == 0 {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return nil, errRollback
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, db.flagUpdateError(ctx, id)
+	}
+
+	// Rewrite the score entries
+	value := func(_ int64) int64 { return flag.Value }
+	if scoring.Mode == api.ScoringModeDynamic && flag.IsDynamic() {
+		value = flag.DynamicValue
+	}
+
+	resp, err := rewriteFlagScores(ctx, tx, id, value)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return nil, errRollback
+		}
+
+		return nil, err
+	}
+
+	// Commit
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
 // DeleteFlag deletes a single flag from the database.
 func (db *DB) DeleteFlag(ctx context.Context, id int64) error {
 	// Delete the database entry
@@ -162,3 +350,7 @@ func (db *DB) ClearFlags(ctx context.Context) error {
 
 	return nil
 }
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
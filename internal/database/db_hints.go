@@ -0,0 +1,263 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/nsec/askgod/api"
+)
+
+// GetTeamHints retrieves all the hints as seen by the team.
+func (db *DB) GetTeamHints(ctx context.Context, teamid int64) ([]api.Hint, error) {
+	// Return a list of hints
+	resp := []api.Hint{}
+
+	// Query all the hints from the database
+	rows, err := db.QueryContext(ctx, "SELECT hint.id, hint.flagid, hint.description, hint.cost, hint.text, score.submit_time FROM hint LEFT JOIN hint_unlock ON hint_unlock.hintid=hint.id AND hint_unlock.teamid=$1 LEFT JOIN score ON score.id=hint_unlock.scoreid ORDER BY hint.id ASC;", teamid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Iterate through the results
+	for rows.Next() {
+		row := api.Hint{}
+		text := ""
+		unlockTime := sql.NullTime{}
+
+		err := rows.Scan(&row.ID, &row.FlagID, &row.Description, &row.Cost, &text, &unlockTime)
+		if err != nil {
+			return nil, err
+		}
+
+		// Only reveal the text of unlocked hints
+		if unlockTime.Valid {
+			row.Unlocked = true
+			row.Text = text
+			row.UnlockTime = unlockTime.Time
+		}
+
+		resp = append(resp, row)
+	}
+
+	// Check for any error that might have happened
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// UnlockTeamHint unlocks a hint for the team and records its cost as a negative score entry.
+func (db *DB) UnlockTeamHint(ctx context.Context, teamid int64, id int64, hint api.HintPost) (*api.Hint, error) {
+	// Query the database entry
+	row, err := db.GetHint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if already unlocked
+	unlockID := int64(-1)
+
+	err = db.QueryRowContext(ctx, "SELECT id FROM hint_unlock WHERE hintid=$1 AND teamid=$2;", row.ID, teamid).Scan(&unlockID)
+	if err == nil {
+		return nil, os.ErrExist
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// Start a transaction
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the cost
+	scoreID := int64(-1)
+	now := time.Now()
+
+	err = tx.QueryRowContext(ctx, "INSERT INTO score (teamid, flagid, value, notes, submit_time, source, type) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id;",
+		teamid, row.FlagID, -row.Cost, row.Description, now, hint.Source, api.ScoreTypeHint).Scan(&scoreID)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return nil, errRollback
+		}
+
+		return nil, err
+	}
+
+	// Record the unlock
+	_, err = tx.ExecContext(ctx, "INSERT INTO hint_unlock (hintid, teamid, scoreid) VALUES ($1, $2, $3);", row.ID, teamid, scoreID)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return nil, errRollback
+		}
+
+		return nil, err
+	}
+
+	// Commit
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.Hint{
+		ID:          row.ID,
+		FlagID:      row.FlagID,
+		Description: row.Description,
+		Cost:        row.Cost,
+		Unlocked:    true,
+		Text:        row.Text,
+		UnlockTime:  now,
+	}, nil
+}
+
+// GetHints retrieves all the hint entries from the database.
+func (db *DB) GetHints(ctx context.Context) ([]api.AdminHint, error) {
+	// Return a list of hints
+	resp := []api.AdminHint{}
+
+	// Query all the hints from the database
+	rows, err := db.QueryContext(ctx, "SELECT id, flagid, description, text, cost FROM hint ORDER BY id ASC;")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Iterate through the results
+	for rows.Next() {
+		row := api.AdminHint{}
+
+		err := rows.Scan(&row.ID, &row.FlagID, &row.Description, &row.Text, &row.Cost)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = append(resp, row)
+	}
+
+	// Check for any error that might have happened
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// GetHint retrieves a single hint entry from the database.
+func (db *DB) GetHint(ctx context.Context, id int64) (*api.AdminHint, error) {
+	// Query the database entry
+	row := api.AdminHint{}
+
+	err := db.QueryRowContext(ctx, "SELECT id, flagid, description, text, cost FROM hint WHERE id=$1;", id).Scan(
+		&row.ID, &row.FlagID, &row.Description, &row.Text, &row.Cost)
+	if err != nil {
+		return nil, err
+	}
+
+	return &row, nil
+}
+
+// CreateHint adds a new hint to the database.
+func (db *DB) CreateHint(ctx context.Context, hint api.AdminHintPost) (int64, error) {
+	id := int64(-1)
+
+	// Create the database entry
+	err := db.QueryRowContext(ctx, "INSERT INTO hint (flagid, description, text, cost) VALUES ($1, $2, $3, $4) RETURNING id",
+		hint.FlagID, hint.Description, hint.Text, hint.Cost).Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// UpdateHint updates an existing hint.
+func (db *DB) UpdateHint(ctx context.Context, id int64, hint api.AdminHintPut) error {
+	// Update the database entry
+	result, err := db.ExecContext(ctx, "UPDATE hint SET flagid=$1, description=$2, text=$3, cost=$4 WHERE id=$5;",
+		hint.FlagID, hint.Description, hint.Text, hint.Cost, id)
+	if err != nil {
+		return err
+	}
+
+	// Check that a change indeed happened
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteHint deletes a single hint from the database.
+func (db *DB) DeleteHint(ctx context.Context, id int64) error {
+	// Delete the database entry
+	result, err := db.ExecContext(ctx, "DELETE FROM hint WHERE id=$1;", id)
+	if err != nil {
+		return err
+	}
+
+	// Check that a change indeed happened
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ClearHints wipes all hint entries from the database.
+func (db *DB) ClearHints(ctx context.Context) error {
+	// Start a transaction
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// Wipe the table
+	_, err = tx.ExecContext(ctx, "DELETE FROM hint;")
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+
+		return err
+	}
+
+	// Reset the sequence
+	_, err = tx.ExecContext(ctx, "ALTER SEQUENCE hint_id_seq RESTART;")
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+
+		return err
+	}
+
+	// Commit
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
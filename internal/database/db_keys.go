@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/nsec/askgod/api"
+)
+
+// GetAdminKeys retrieves all the admin keys from the database (without the keys themselves).
+func (db *DB) GetAdminKeys(ctx context.Context) ([]api.AdminKey, error) {
+	// Return a list of keys
+	resp := []api.AdminKey{}
+
+	// Query all the keys from the database
+	rows, err := db.QueryContext(ctx, "SELECT id, name, role, created_at, last_used FROM admin_key ORDER BY id ASC;")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Iterate through the results
+	for rows.Next() {
+		row := api.AdminKey{}
+		lastUsed := sql.NullTime{}
+
+		err := rows.Scan(&row.ID, &row.Name, &row.Role, &row.CreatedAt, &lastUsed)
+		if err != nil {
+			return nil, err
+		}
+
+		row.LastUsed = lastUsed.Time
+
+		resp = append(resp, row)
+	}
+
+	// Check for any error that might have happened
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// CreateAdminKey issues a new random admin key.
+// The key is only returned here, the database only keeping its hash.
+func (db *DB) CreateAdminKey(ctx context.Context, key api.AdminKeyPost) (*api.AdminKey, error) {
+	// Generate the key
+	secret, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := api.AdminKey{
+		AdminKeyPost: key,
+		CreatedAt:    time.Now(),
+		Key:          secret,
+	}
+
+	// Create the database entry
+	err = db.QueryRowContext(ctx, "INSERT INTO admin_key (name, role, hash, created_at) VALUES ($1, $2, $3, $4) RETURNING id",
+		key.Name, key.Role, hashToken(resp.Key), resp.CreatedAt).Scan(&resp.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// DeleteAdminKey revokes an admin key.
+func (db *DB) DeleteAdminKey(ctx context.Context, id int64) error {
+	// Delete the database entry
+	result, err := db.ExecContext(ctx, "DELETE FROM admin_key WHERE id=$1;", id)
+	if err != nil {
+		return err
+	}
+
+	// Check that a change indeed happened
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetAdminKeyForToken retrieves the admin key matching the provided token.
+func (db *DB) GetAdminKeyForToken(ctx context.Context, token string) (*api.AdminKey, error) {
+	resp := api.AdminKey{}
+	lastUsed := sql.NullTime{}
+
+	// Find the key
+	err := db.QueryRowContext(ctx, "SELECT id, name, role, created_at, last_used FROM admin_key WHERE hash=$1;", hashToken(token)).Scan(&resp.ID, &resp.Name, &resp.Role, &resp.CreatedAt, &lastUsed)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.LastUsed = lastUsed.Time
+
+	// Record its use
+	now := time.Now()
+	if !lastUsed.Valid || now.Sub(lastUsed.Time) >= tokenUsageInterval {
+		_, err = db.ExecContext(ctx, "UPDATE admin_key SET last_used=$1 WHERE id=$2;", now, resp.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.LastUsed = now
+	}
+
+	return &resp, nil
+}
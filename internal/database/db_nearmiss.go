@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/nsec/askgod/api"
+	"github.com/nsec/askgod/internal/utils"
+)
+
+// FindNearMiss looks for the flag (or team variant) closest to an invalid input.
+// It returns the flag ID and the reason of the near miss, or sql.ErrNoRows if nothing is close enough.
+func (db *DB) FindNearMiss(ctx context.Context, teamid int64, input string) (int64, string, error) {
+	// Don't bother comparing overly long inputs
+	if len(input) > utils.NearMissMaxLength {
+		return -1, "", sql.ErrNoRows
+	}
+
+	// Query all the flags the team could submit
+	rows, err := db.QueryContext(ctx, "SELECT id, flag FROM flag WHERE NOT EXISTS (SELECT 1 FROM flag_variant WHERE flag_variant.flagid=flag.id) UNION ALL SELECT flagid, flag FROM flag_variant WHERE teamid=$1;", teamid)
+	if err != nil {
+		return -1, "", err
+	}
+	defer rows.Close()
+
+	// Find the closest match
+	closestID := int64(-1)
+	closestReason := ""
+	closestDistance := -1
+
+	for rows.Next() {
+		id := int64(-1)
+		flag := ""
+
+		err := rows.Scan(&id, &flag)
+		if err != nil {
+			return -1, "", err
+		}
+
+		reason, distance := utils.NearMiss(input, flag)
+		if reason == "" {
+			continue
+		}
+
+		if closestDistance == -1 || distance < closestDistance {
+			closestID = id
+			closestReason = reason
+			closestDistance = distance
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return -1, "", err
+	}
+
+	if closestID == -1 {
+		return -1, "", sql.ErrNoRows
+	}
+
+	return closestID, closestReason, nil
+}
+
+// GetNearMisses aggregates the near-miss submissions per flag, most affected flags first.
+func (db *DB) GetNearMisses(ctx context.Context) ([]api.AdminNearMiss, error) {
+	// Return a list of flags
+	resp := []api.AdminNearMiss{}
+
+	// Query the aggregated submissions
+	rows, err := db.QueryContext(ctx, "SELECT flagid, COUNT(*), COUNT(DISTINCT teamid), MAX(submit_time) FROM submission WHERE outcome=$1 AND flagid IS NOT NULL GROUP BY flagid ORDER BY COUNT(DISTINCT teamid) DESC, COUNT(*) DESC;", api.SubmissionNearMiss)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Iterate through the results
+	for rows.Next() {
+		row := api.AdminNearMiss{}
+
+		err := rows.Scan(&row.FlagID, &row.Attempts, &row.Teams, &row.LastSubmitTime)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = append(resp, row)
+	}
+
+	// Check for any error that might have happened
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// RateLimitMaxLockout is the longest a key may get locked out for.
+const RateLimitMaxLockout = 365 * 24 * time.Hour
+
+// GetRateLimit returns how long the provided key remains locked out (zero if it isn't).
+func (db *DB) GetRateLimit(ctx context.Context, key string) (time.Duration, error) {
+	lockedUntil := sql.NullTime{}
+
+	err := db.QueryRowContext(ctx, "SELECT locked_until FROM ratelimit WHERE key=$1;", key).Scan(&lockedUntil)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	if !lockedUntil.Valid {
+		return 0, nil
+	}
+
+	return max(time.Until(lockedUntil.Time), 0), nil
+}
+
+// RecordRateLimitAttempt records an invalid attempt for the provided key.
+// Once limit attempts are reached within window, the key gets locked out for
+// cooldown, doubling with every consecutive lockout (up to RateLimitMaxLockout). It returns the length of
+// the new lockout (zero if none was triggered).
+func (db *DB) RecordRateLimitAttempt(ctx context.Context, key string, limit int64, window time.Duration, cooldown time.Duration) (time.Duration, error) {
+	// Start a transaction
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+
+	// Make sure the entry exists
+	_, err = tx.ExecContext(ctx, "INSERT INTO ratelimit (key, attempts, window_start, lockouts) VALUES ($1, 0, $2, 0) ON CONFLICT (key) DO NOTHING;", key, now)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return 0, errRollback
+		}
+
+		return 0, err
+	}
+
+	// Lock and fetch the current state
+	attempts := int64(0)
+	windowStart := time.Time{}
+	lockouts := int64(0)
+	lockedUntil := sql.NullTime{}
+
+	err = tx.QueryRowContext(ctx, "SELECT attempts, window_start, lockouts, locked_until FROM ratelimit WHERE key=$1 FOR UPDATE;", key).Scan(&attempts, &windowStart, &lockouts, &lockedUntil)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return 0, errRollback
+		}
+
+		return 0, err
+	}
+
+	// Start a new window if needed
+	if now.Sub(windowStart) > window {
+		attempts = 0
+		windowStart = now
+
+		// Forget about past lockouts after a quiet window
+		if !lockedUntil.Valid || now.Sub(lockedUntil.Time) > window {
+			lockouts = 0
+		}
+	}
+
+	attempts++
+
+	// Lock out if over the limit
+	lockout := time.Duration(0)
+
+	if attempts >= limit {
+		shift := min(lockouts, 10)
+
+		lockout = RateLimitMaxLockout
+		if cooldown <= RateLimitMaxLockout>>shift {
+			lockout = cooldown << shift
+		}
+
+		lockouts++
+		attempts = 0
+		windowStart = now
+		lockedUntil = sql.NullTime{Time: now.Add(lockout), Valid: true}
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE ratelimit SET attempts=$1, window_start=$2, lockouts=$3, locked_until=$4 WHERE key=$5;", attempts, windowStart, lockouts, lockedUntil, key)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return 0, errRollback
+		}
+
+		return 0, err
+	}
+
+	// Commit
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return lockout, nil
+}
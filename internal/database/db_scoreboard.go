@@ -1,19 +1,79 @@
 package database
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/nsec/askgod/api"
 )
 
-// GetScoreboard generates the current scoreboard.
-func (db *DB) GetScoreboard(ctx context.Context) ([]api.ScoreboardEntry, error) {
+// scoreFilterCondition is the SQL condition applying a ScoreFilter (except for its division) to the score table.
+const scoreFilterCondition = "($1::TIMESTAMPTZ IS NULL OR score.submit_time < $1 OR score.teamid=$2) AND ($5::TIMESTAMPTZ IS NULL OR score.submit_time <= $5) AND ($3 = '' OR $3 = ANY(string_to_array(flag.tags, ',')))"
+
+// scoreFilterArgs returns the SQL arguments matching scoreFilterCondition.
+func scoreFilterArgs(filter ScoreFilter) []any {
+	return []any{nullTime(filter.Before), filter.TeamID, filter.Tag, filter.Division, nullTime(filter.At)}
+}
+
+// filteredScoreEntries returns the score table expression to use for the filter.
+// With DynamicValues, the solves of dynamic flags are valued as they were when
+// only the solves let through by the filter had happened.
+func (db *DB) filteredScoreEntries(ctx context.Context, filter ScoreFilter) (string, error) {
+	if !filter.DynamicValues || (filter.Before.IsZero() && filter.At.IsZero()) {
+		return scoreEntries, nil
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT flag.id, flag.value, flag.initial_value, flag.minimum_value, flag.decay, COUNT(score.id) FROM flag LEFT JOIN score ON score.flagid=flag.id AND score.type=$3 AND ($1::TIMESTAMPTZ IS NULL OR score.submit_time < $1 OR score.teamid=$2) AND ($4::TIMESTAMPTZ IS NULL OR score.submit_time <= $4) WHERE flag.decay > 0 GROUP BY flag.id;",
+		nullTime(filter.Before), filter.TeamID, api.ScoreTypeSolve, nullTime(filter.At))
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	// Build the value of each dynamic flag
+	values := ""
+
+	for rows.Next() {
+		flagID := int64(0)
+		flag := api.AdminFlagPut{}
+		solves := int64(0)
+
+		err := rows.Scan(&flagID, &flag.Value, &flag.InitialValue, &flag.MinimumValue, &flag.Decay, &solves)
+		if err != nil {
+			return "", err
+		}
+
+		values += fmt.Sprintf(" WHEN %d THEN %d", flagID, flag.DynamicValue(solves))
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return "", err
+	}
+
+	if values == "" {
+		return scoreEntries, nil
+	}
+
+	return fmt.Sprintf(scoreEntriesValued, "CASE WHEN type='"+api.ScoreTypeSolve+"' THEN CASE flagid"+values+" ELSE value END ELSE value END"), nil
+}
+
+// GetScoreboard generates the current scoreboard, ranking tied teams according to the tiebreak policy.
+func (db *DB) GetScoreboard(ctx context.Context, filter ScoreFilter, tiebreak string) ([]api.ScoreboardEntry, error) {
 	// Return a list of score entries
 	resp := []api.ScoreboardEntry{}
 
+	entries, err := db.filteredScoreEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
 	// Query all the scores from the database
-	rows, err := db.QueryContext(ctx, "SELECT team.id, team.country, team.name, team.website, COALESCE(SUM(score.value), 0) AS points, MAX(score.submit_time) AS last_submit_time FROM score RIGHT JOIN team ON team.id=score.teamid WHERE team.name != '' AND team.country != '' GROUP BY team.id ORDER BY points DESC, last_submit_time ASC;")
+	rows, err := db.QueryContext(ctx, "SELECT team.id, team.country, team.name, team.website, team.division, COALESCE(SUM(score.value), 0) AS points, MAX(score.submit_time) AS last_submit_time FROM "+entries+" LEFT JOIN flag ON flag.id=score.flagid RIGHT JOIN team ON team.id=score.teamid AND "+scoreFilterCondition+" WHERE team.name != '' AND team.country != '' AND ($4 = '' OR team.division=$4) GROUP BY team.id ORDER BY points DESC, last_submit_time ASC;", scoreFilterArgs(filter)...)
 	if err != nil {
 		return nil, err
 	}
@@ -25,7 +85,7 @@ func (db *DB) GetScoreboard(ctx context.Context) ([]api.ScoreboardEntry, error)
 
 		submitTime := sql.NullTime{}
 
-		err := rows.Scan(&row.Team.ID, &row.Team.Country, &row.Team.Name, &row.Team.Website, &row.Value, &submitTime)
+		err := rows.Scan(&row.Team.ID, &row.Team.Country, &row.Team.Name, &row.Team.Website, &row.Team.Division, &row.Value, &submitTime)
 		if err != nil {
 			return nil, err
 		}
@@ -41,5 +101,125 @@ func (db *DB) GetScoreboard(ctx context.Context) ([]api.ScoreboardEntry, error)
 		return nil, err
 	}
 
+	// Break the ties
+	switch tiebreak {
+	case api.TiebreakFirstReach:
+		reachTimes, err := db.getScoreReachTimes(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		slices.SortStableFunc(resp, func(a api.ScoreboardEntry, b api.ScoreboardEntry) int {
+			if a.Value != b.Value {
+				return cmp.Compare(b.Value, a.Value)
+			}
+
+			return compareTimes(reachTimes[a.Team.ID], reachTimes[b.Team.ID])
+		})
+	case api.TiebreakFirstBloods:
+		firstBloods, err := db.getFirstBloodCounts(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		slices.SortStableFunc(resp, func(a api.ScoreboardEntry, b api.ScoreboardEntry) int {
+			if a.Value != b.Value {
+				return cmp.Compare(b.Value, a.Value)
+			}
+
+			return cmp.Compare(firstBloods[b.Team.ID], firstBloods[a.Team.ID])
+		})
+	}
+
+	// Assign the ranks
+	for i := range resp {
+		resp[i].Rank = int64(i + 1)
+
+		if tiebreak == api.TiebreakShared && i > 0 && resp[i].Value == resp[i-1].Value {
+			resp[i].Rank = resp[i-1].Rank
+		}
+	}
+
 	return resp, nil
 }
+
+// getScoreReachTimes returns the time at which each team first reached its current score.
+func (db *DB) getScoreReachTimes(ctx context.Context, filter ScoreFilter) (map[int64]time.Time, error) {
+	resp := map[int64]time.Time{}
+
+	entries, err := db.filteredScoreEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT teamid, MIN(submit_time) FROM (SELECT score.teamid, score.submit_time, SUM(score.value) OVER (PARTITION BY score.teamid ORDER BY score.submit_time, score.id) AS running, SUM(score.value) OVER (PARTITION BY score.teamid) AS total FROM "+entries+" LEFT JOIN flag ON flag.id=score.flagid LEFT JOIN team ON team.id=score.teamid WHERE "+scoreFilterCondition+" AND ($4 = '' OR team.division=$4)) AS totals WHERE running >= total GROUP BY teamid;", scoreFilterArgs(filter)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var teamid int64
+
+		var reachTime time.Time
+
+		err := rows.Scan(&teamid, &reachTime)
+		if err != nil {
+			return nil, err
+		}
+
+		resp[teamid] = reachTime
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// getFirstBloodCounts returns the number of flags each team was the first to solve.
+func (db *DB) getFirstBloodCounts(ctx context.Context, filter ScoreFilter) (map[int64]int64, error) {
+	resp := map[int64]int64{}
+
+	rows, err := db.QueryContext(ctx, "SELECT score.teamid, COUNT(*) FROM score LEFT JOIN flag ON flag.id=score.flagid LEFT JOIN team ON team.id=score.teamid WHERE "+scoreFilterCondition+" AND ($4 = '' OR team.division=$4) AND score.type=$6 AND NOT EXISTS (SELECT 1 FROM score AS other WHERE other.flagid=score.flagid AND other.type=$6 AND other.submit_time < score.submit_time) GROUP BY score.teamid;", append(scoreFilterArgs(filter), api.ScoreTypeSolve)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var teamid int64
+
+		var count int64
+
+		err := rows.Scan(&teamid, &count)
+		if err != nil {
+			return nil, err
+		}
+
+		resp[teamid] = count
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// compareTimes orders times with the zero value (never) last.
+func compareTimes(a time.Time, b time.Time) int {
+	switch {
+	case a.Equal(b):
+		return 0
+	case a.IsZero():
+		return 1
+	case b.IsZero():
+		return -1
+	}
+
+	return a.Compare(b)
+}
@@ -4,19 +4,41 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"os"
 	"time"
 
+	"github.com/lib/pq"
+
 	"github.com/nsec/askgod/api"
-	"github.com/nsec/askgod/internal/utils"
 )
 
+var (
+	// ErrFlagLocked indicates that the prerequisites of a flag haven't been solved yet.
+	ErrFlagLocked = errors.New("flag is locked")
+
+	// ErrFlagNotActive indicates that a flag can't be submitted yet.
+	ErrFlagNotActive = errors.New("flag isn't active yet")
+
+	// ErrFlagExpired indicates that a flag can no longer be submitted.
+	ErrFlagExpired = errors.New("flag has expired")
+)
+
+// FlagSharedError indicates that a team submitted the flag variant of another team.
+type FlagSharedError struct {
+	TeamID int64
+}
+
+func (e *FlagSharedError) Error() string {
+	return fmt.Sprintf("flag variant belongs to team %d", e.TeamID)
+}
+
 // GetTeamPoints returns the current total for the team.
 func (db *DB) GetTeamPoints(ctx context.Context, teamid int64) (int64, error) {
 	total := int64(0)
 
 	// Get the total
-	err := db.QueryRowContext(ctx, "SELECT COALESCE(SUM(score.value), 0) AS points FROM score WHERE teamid=$1", teamid).Scan(&total)
+	err := db.QueryRowContext(ctx, "SELECT COALESCE(SUM(score.value), 0) AS points FROM "+scoreEntries+" WHERE teamid=$1", teamid).Scan(&total)
 	if err != nil {
 		return -1, err
 	}
@@ -30,7 +52,7 @@ func (db *DB) GetTeamFlags(ctx context.Context, teamid int64) ([]api.Flag, error
 	resp := []api.Flag{}
 
 	// Query all the scores from the database
-	rows, err := db.QueryContext(ctx, "SELECT score.flagid, flag.description, score.value, score.notes, score.source, score.submit_time, flag.return_string FROM score LEFT JOIN flag ON flag.id=score.flagid WHERE score.teamid=$1 ORDER BY score.submit_time ASC;", teamid)
+	rows, err := db.QueryContext(ctx, "SELECT score.flagid, flag.description, score.value, score.notes, score.source, score.submit_time, flag.return_string, score.type, COALESCE(flag.trap, false) FROM score LEFT JOIN flag ON flag.id=score.flagid WHERE score.teamid=$1 ORDER BY score.submit_time ASC;", teamid)
 	if err != nil {
 		return nil, err
 	}
@@ -40,7 +62,7 @@ func (db *DB) GetTeamFlags(ctx context.Context, teamid int64) ([]api.Flag, error
 	for rows.Next() {
 		row := api.Flag{}
 
-		err := rows.Scan(&row.ID, &row.Description, &row.Value, &row.Notes, &row.Source, &row.SubmitTime, &row.ReturnString)
+		err := rows.Scan(&row.ID, &row.Description, &row.Value, &row.Notes, &row.Source, &row.SubmitTime, &row.ReturnString, &row.Type, &row.Trap)
 		if err != nil {
 			return nil, err
 		}
@@ -63,8 +85,8 @@ func (db *DB) GetTeamFlag(ctx context.Context, teamid int64, id int64) (*api.Fla
 	resp := api.Flag{}
 
 	// Query all the scores from the database
-	err := db.QueryRowContext(ctx, "SELECT score.flagid, flag.description, score.value, score.notes, score.source, score.submit_time, flag.return_string FROM score LEFT JOIN flag ON flag.id=score.flagid WHERE score.teamid=$1 AND score.flagid=$2 ORDER BY score.submit_time ASC;", teamid, id).Scan(
-		&resp.ID, &resp.Description, &resp.Value, &resp.Notes, &resp.Source, &resp.SubmitTime, &resp.ReturnString)
+	err := db.QueryRowContext(ctx, "SELECT score.flagid, flag.description, score.value, score.notes, score.source, score.submit_time, flag.return_string, score.type, COALESCE(flag.trap, false) FROM score LEFT JOIN flag ON flag.id=score.flagid WHERE score.teamid=$1 AND score.flagid=$2 AND score.type='solve' ORDER BY score.submit_time ASC;", teamid, id).Scan(
+		&resp.ID, &resp.Description, &resp.Value, &resp.Notes, &resp.Source, &resp.SubmitTime, &resp.ReturnString, &resp.Type, &resp.Trap)
 	if err != nil {
 		return nil, err
 	}
@@ -75,7 +97,7 @@ func (db *DB) GetTeamFlag(ctx context.Context, teamid int64, id int64) (*api.Fla
 // UpdateTeamFlag updates a single score entry for the team.
 func (db *DB) UpdateTeamFlag(ctx context.Context, teamid int64, id int64, flag api.FlagPut) error {
 	// Update the database entry
-	result, err := db.ExecContext(ctx, "UPDATE score SET notes=$1 WHERE teamid=$2 AND flagid=$3;",
+	result, err := db.ExecContext(ctx, "UPDATE score SET notes=$1 WHERE teamid=$2 AND flagid=$3 AND type='solve';",
 		flag.Notes, teamid, id)
 	if err != nil {
 		return err
@@ -95,18 +117,25 @@ func (db *DB) UpdateTeamFlag(ctx context.Context, teamid int64, id int64, flag a
 }
 
 // SubmitTeamFlag validates a submitted flag and adds it to the database.
-func (db *DB) SubmitTeamFlag(ctx context.Context, teamid int64, flag api.FlagPost) (*api.Flag, *api.AdminFlag, error) {
-	// Query the database entry
-	row := api.AdminFlag{}
-	tags := ""
-
-	err := db.QueryRowContext(ctx, "SELECT id, flag, value, return_string, description, tags FROM flag WHERE LOWER(flag)=LOWER($1);", flag.Flag).Scan(
-		&row.ID, &row.Flag, &row.Value, &row.ReturnString, &row.Description, &tags)
+func (db *DB) SubmitTeamFlag(ctx context.Context, teamid int64, flag api.FlagPost, scoring api.ConfigScoring) (*api.Flag, *api.AdminFlag, error) {
+	// Find the matching flag
+	flagid, err := db.lookupFlag(ctx, teamid, flag.Flag)
 	if err != nil {
-		return nil, nil, err
+		var errShared *FlagSharedError
+		if !errors.As(err, &errShared) {
+			return nil, nil, err
+		}
+
+		row, errFlag := db.GetFlag(ctx, flagid)
+		if errFlag != nil {
+			return nil, nil, errFlag
+		}
+
+		return nil, row, err
 	}
 
-	row.Tags, err = utils.ParseTags(tags)
+	// Query the database entry
+	row, err := db.GetFlag(ctx, flagid)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -114,18 +143,57 @@ func (db *DB) SubmitTeamFlag(ctx context.Context, teamid int64, flag api.FlagPos
 	// Check if already submitted
 	id := int64(-1)
 
-	err = db.QueryRowContext(ctx, "SELECT id FROM score WHERE teamid=$1 AND flagid=$2;", teamid, row.ID).Scan(&id)
+	err = db.QueryRowContext(ctx, "SELECT id FROM score WHERE teamid=$1 AND flagid=$2 AND type='solve';", teamid, row.ID).Scan(&id)
 	if err == nil {
-		return nil, &row, os.ErrExist
+		return nil, row, os.ErrExist
 	} else if !errors.Is(err, sql.ErrNoRows) {
 		return nil, nil, err
 	}
 
+	// Check that the flag can be submitted at this time
+	now := time.Now()
+
+	if !row.ValidFrom.IsZero() && now.Before(row.ValidFrom) {
+		return nil, row, ErrFlagNotActive
+	}
+
+	if !row.ValidUntil.IsZero() && now.After(row.ValidUntil) {
+		return nil, row, ErrFlagExpired
+	}
+
+	// Check that the prerequisites are met
+	if len(row.Requires) > 0 {
+		solved := int64(0)
+
+		err = db.QueryRowContext(ctx, "SELECT COUNT(DISTINCT flagid) FROM score WHERE teamid=$1 AND type='solve' AND flagid=ANY($2);", teamid, pq.Array(row.Requires)).Scan(&solved)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if solved < int64(len(row.Requires)) {
+			return nil, row, ErrFlagLocked
+		}
+	}
+
+	// Figure out the value of the flag
+	value := row.Value
+
+	if scoring.Mode == api.ScoringModeDynamic && row.IsDynamic() {
+		solves := int64(0)
+
+		err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM score WHERE flagid=$1 AND type='solve';", row.ID).Scan(&solves)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		value = row.DynamicValue(solves + 1)
+	}
+
 	// Add the flag
 	id = -1
 
 	err = db.QueryRowContext(ctx, "INSERT INTO score (teamid, flagid, value, notes, submit_time, source) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id;",
-		teamid, row.ID, row.Value, flag.Notes, time.Now(), flag.Source).Scan(&id)
+		teamid, row.ID, value, flag.Notes, time.Now(), flag.Source).Scan(&id)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -133,13 +201,157 @@ func (db *DB) SubmitTeamFlag(ctx context.Context, teamid int64, flag api.FlagPos
 	// Query the new entry
 	result := api.Flag{}
 
-	err = db.QueryRowContext(ctx, "SELECT score.flagid, flag.description, score.value, score.notes, score.source, score.submit_time, flag.return_string FROM score LEFT JOIN flag ON flag.id=score.flagid WHERE score.id=$1;", id).Scan(
-		&result.ID, &result.Description, &result.Value, &result.Notes, &result.Source, &result.SubmitTime, &result.ReturnString)
+	err = db.QueryRowContext(ctx, "SELECT score.flagid, flag.description, score.value, score.notes, score.source, score.submit_time, flag.return_string, score.type, COALESCE(flag.trap, false) FROM score LEFT JOIN flag ON flag.id=score.flagid WHERE score.id=$1;", id).Scan(
+		&result.ID, &result.Description, &result.Value, &result.Notes, &result.Source, &result.SubmitTime, &result.ReturnString, &result.Type, &result.Trap)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return &result, &row, nil
+	return &result, row, nil
+}
+
+func (db *DB) lookupFlag(ctx context.Context, teamid int64, input string) (int64, error) {
+	id := int64(-1)
+
+	// Look for a shared flag (teams with a variant of the flag can only submit it through that variant)
+	err := db.QueryRowContext(ctx, "SELECT id FROM flag WHERE LOWER(flag)=LOWER($1) AND NOT EXISTS (SELECT 1 FROM flag_variant WHERE flag_variant.flagid=flag.id AND flag_variant.teamid=$2);", input, teamid).Scan(&id)
+	if err == nil {
+		return id, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return -1, err
+	}
+
+	// Look for a team variant
+	owner := int64(-1)
+
+	err = db.QueryRowContext(ctx, "SELECT flagid, teamid FROM flag_variant WHERE LOWER(flag)=LOWER($1);", input).Scan(&id, &owner)
+	if err != nil {
+		return -1, err
+	}
+
+	if owner != teamid {
+		return id, &FlagSharedError{TeamID: owner}
+	}
+
+	return id, nil
+}
+
+// AwardSolveBonus gives the team its bonus if it's one of the first solvers of the flag.
+// It returns the team's rank among the solvers and the bonus value (0 if none was awarded).
+func (db *DB) AwardSolveBonus(ctx context.Context, teamid int64, flagid int64, bonuses []int64) (int64, int64, error) {
+	// Get the solve entry
+	id := int64(-1)
+	submitTime := time.Time{}
+	source := ""
+
+	err := db.QueryRowContext(ctx, "SELECT id, submit_time, source FROM score WHERE teamid=$1 AND flagid=$2 AND type='solve';", teamid, flagid).Scan(&id, &submitTime, &source)
+	if err != nil {
+		return -1, 0, err
+	}
+
+	// Figure out the rank
+	rank := int64(0)
+
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM score WHERE flagid=$1 AND type='solve' AND id<=$2;", flagid, id).Scan(&rank)
+	if err != nil {
+		return -1, 0, err
+	}
+
+	if rank < 1 || rank > int64(len(bonuses)) || bonuses[rank-1] == 0 {
+		return rank, 0, nil
+	}
+
+	// Add the bonus
+	value := bonuses[rank-1]
+
+	_, err = db.ExecContext(ctx, "INSERT INTO score (teamid, flagid, value, notes, submit_time, source, type) VALUES ($1, $2, $3, $4, $5, $6, $7);",
+		teamid, flagid, value, fmt.Sprintf("Solver #%d bonus", rank), submitTime, source, api.ScoreTypeBonus)
+	if err != nil {
+		return -1, 0, err
+	}
+
+	return rank, value, nil
+}
+
+// RecomputeFlagScores re-applies the decay curve of a dynamic flag to all its score entries.
+// It returns the change in points for every team whose score entry was modified.
+func (db *DB) RecomputeFlagScores(ctx context.Context, flag api.AdminFlag) (map[int64]int64, error) {
+	// Start a transaction
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the new value
+	resp, err := rewriteFlagScores(ctx, tx, flag.ID, flag.DynamicValue)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return nil, errRollback
+		}
+
+		return nil, err
+	}
+
+	// Commit
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// rewriteFlagScores sets the value of all the solves of a flag, as computed from their number.
+// It returns the change in points for every team whose score entry was modified.
+func rewriteFlagScores(ctx context.Context, tx *sql.Tx, flagid int64, value func(solves int64) int64) (map[int64]int64, error) {
+	// Lock and fetch the current entries
+	rows, err := tx.QueryContext(ctx, "SELECT teamid, value FROM score WHERE flagid=$1 AND type='solve' FOR UPDATE;", flagid)
+	if err != nil {
+		return nil, err
+	}
+
+	current := map[int64]int64{}
+
+	for rows.Next() {
+		teamid := int64(-1)
+		oldValue := int64(0)
+
+		err := rows.Scan(&teamid, &oldValue)
+		if err != nil {
+			_ = rows.Close()
+
+			return nil, err
+		}
+
+		current[teamid] = oldValue
+	}
+
+	err = rows.Err()
+	_ = rows.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the new value
+	newValue := value(int64(len(current)))
+
+	_, err = tx.ExecContext(ctx, "UPDATE score SET value=$1 WHERE flagid=$2 AND type='solve' AND value!=$1;", newValue, flagid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compute the differences
+	resp := map[int64]int64{}
+
+	for teamid, oldValue := range current {
+		if oldValue != newValue {
+			resp[teamid] = newValue - oldValue
+		}
+	}
+
+	return resp, nil
 }
 
 // GetScores retrieves all the score entries from the database.
@@ -148,7 +360,7 @@ func (db *DB) GetScores(ctx context.Context) ([]api.AdminScore, error) {
 	resp := []api.AdminScore{}
 
 	// Query all the scores from the database
-	rows, err := db.QueryContext(ctx, "SELECT id, teamid, flagid, value, notes, source, submit_time FROM score ORDER BY id ASC;")
+	rows, err := db.QueryContext(ctx, "SELECT id, teamid, flagid, value, notes, source, submit_time, type FROM score ORDER BY id ASC;")
 	if err != nil {
 		return nil, err
 	}
@@ -158,7 +370,7 @@ func (db *DB) GetScores(ctx context.Context) ([]api.AdminScore, error) {
 	for rows.Next() {
 		row := api.AdminScore{}
 
-		err := rows.Scan(&row.ID, &row.TeamID, &row.FlagID, &row.Value, &row.Notes, &row.Source, &row.SubmitTime)
+		err := rows.Scan(&row.ID, &row.TeamID, &row.FlagID, &row.Value, &row.Notes, &row.Source, &row.SubmitTime, &row.Type)
 		if err != nil {
 			return nil, err
 		}
@@ -180,8 +392,8 @@ func (db *DB) GetScore(ctx context.Context, id int64) (*api.AdminScore, error) {
 	// Query the database entry
 	row := api.AdminScore{}
 
-	err := db.QueryRowContext(ctx, "SELECT id, teamid, flagid, value, notes, source, submit_time FROM score WHERE id=$1;", id).Scan(
-		&row.ID, &row.TeamID, &row.FlagID, &row.Value, &row.Notes, &row.Source, &row.SubmitTime)
+	err := db.QueryRowContext(ctx, "SELECT id, teamid, flagid, value, notes, source, submit_time, type FROM score WHERE id=$1;", id).Scan(
+		&row.ID, &row.TeamID, &row.FlagID, &row.Value, &row.Notes, &row.Source, &row.SubmitTime, &row.Type)
 	if err != nil {
 		return nil, err
 	}
@@ -194,8 +406,8 @@ func (db *DB) CreateScore(ctx context.Context, score api.AdminScorePost) (int64,
 	id := int64(-1)
 
 	// Create the database entry
-	err := db.QueryRowContext(ctx, "INSERT INTO score (teamid, flagid, value, notes, source, submit_time) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
-		score.TeamID, score.FlagID, score.Value, score.Notes, score.Source, time.Now()).Scan(&id)
+	err := db.QueryRowContext(ctx, "INSERT INTO score (teamid, flagid, value, notes, source, submit_time, type) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		score.TeamID, score.FlagID, score.Value, score.Notes, score.Source, time.Now(), score.Type).Scan(&id)
 	if err != nil {
 		return -1, err
 	}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/nsec/askgod/api"
+)
+
+// GetSubmissions retrieves the submission attempts matching the filter, oldest first.
+func (db *DB) GetSubmissions(ctx context.Context, filter SubmissionFilter) ([]api.AdminSubmission, error) {
+	// Return a list of submissions
+	resp := []api.AdminSubmission{}
+
+	// Query the most recent matching submissions from the database
+	rows, err := db.QueryContext(ctx, "SELECT id, teamid, flagid, ip, input, outcome, source, submit_time FROM submission WHERE ($1 = 0 OR teamid=$1) AND ($2 = 0 OR flagid=$2) AND ($3 = '' OR ip=$3) AND ($4 = '' OR outcome=$4) AND ($5 = '' OR source=$5) AND ($6::TIMESTAMPTZ IS NULL OR submit_time >= $6) AND ($7::TIMESTAMPTZ IS NULL OR submit_time < $7) ORDER BY id DESC LIMIT NULLIF($8, 0);",
+		filter.TeamID, filter.FlagID, filter.IP, filter.Outcome, filter.Source, nullTime(filter.Since), nullTime(filter.Until), filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Iterate through the results
+	for rows.Next() {
+		row := api.AdminSubmission{}
+		flagid := sql.NullInt64{}
+
+		err := rows.Scan(&row.ID, &row.TeamID, &flagid, &row.IP, &row.Input, &row.Outcome, &row.Source, &row.SubmitTime)
+		if err != nil {
+			return nil, err
+		}
+
+		row.FlagID = flagid.Int64
+
+		resp = append(resp, row)
+	}
+
+	// Check for any error that might have happened
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(resp)
+
+	return resp, nil
+}
+
+// CreateSubmission records a new submission attempt.
+func (db *DB) CreateSubmission(ctx context.Context, submission api.AdminSubmission) (int64, error) {
+	id := int64(-1)
+
+	// Create the database entry
+	err := db.QueryRowContext(ctx, "INSERT INTO submission (teamid, flagid, ip, input, outcome, source, submit_time) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		submission.TeamID, sql.NullInt64{Int64: submission.FlagID, Valid: submission.FlagID > 0}, submission.IP, submission.Input, submission.Outcome, submission.Source, time.Now()).Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
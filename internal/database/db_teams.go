@@ -4,22 +4,27 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"maps"
 	"net"
-	"strings"
 
 	"github.com/inconshreveable/log15"
 
 	"github.com/nsec/askgod/api"
+	"github.com/nsec/askgod/internal/subnets"
 	"github.com/nsec/askgod/internal/utils"
 )
 
+// ErrTeamSubnets indicates that the subnets of a team are invalid or overlap with those of another team.
+var ErrTeamSubnets = errors.New("invalid team subnets")
+
 // GetTeams retrieves all the team entries from the database.
 func (db *DB) GetTeams(ctx context.Context) ([]api.AdminTeam, error) {
 	// Return a list of teams
 	resp := []api.AdminTeam{}
 
 	// Query all the teams from the database
-	rows, err := db.QueryContext(ctx, "SELECT id, name, country, website, notes, subnets, tags FROM team ORDER BY id ASC;")
+	rows, err := db.QueryContext(ctx, "SELECT id, name, country, website, notes, subnets, tags, division FROM team ORDER BY id ASC;")
 	if err != nil {
 		return nil, err
 	}
@@ -30,7 +35,7 @@ func (db *DB) GetTeams(ctx context.Context) ([]api.AdminTeam, error) {
 		row := api.AdminTeam{}
 		tags := ""
 
-		err := rows.Scan(&row.ID, &row.Name, &row.Country, &row.Website, &row.Notes, &row.Subnets, &tags)
+		err := rows.Scan(&row.ID, &row.Name, &row.Country, &row.Website, &row.Notes, &row.Subnets, &tags, &row.Division)
 		if err != nil {
 			return nil, err
 		}
@@ -58,8 +63,8 @@ func (db *DB) GetTeam(ctx context.Context, id int64) (*api.AdminTeam, error) {
 	row := api.AdminTeam{}
 	tags := ""
 
-	err := db.QueryRowContext(ctx, "SELECT id, name, country, website, notes, subnets, tags FROM team WHERE id=$1;", id).Scan(
-		&row.ID, &row.Name, &row.Country, &row.Website, &row.Notes, &row.Subnets, &tags)
+	err := db.QueryRowContext(ctx, "SELECT id, name, country, website, notes, subnets, tags, division FROM team WHERE id=$1;", id).Scan(
+		&row.ID, &row.Name, &row.Country, &row.Website, &row.Notes, &row.Subnets, &tags, &row.Division)
 	if err != nil {
 		return nil, err
 	}
@@ -74,83 +79,283 @@ func (db *DB) GetTeam(ctx context.Context, id int64) (*api.AdminTeam, error) {
 
 // GetTeamForIP retrieves a single team entry for the provided IP.
 func (db *DB) GetTeamForIP(ctx context.Context, ip net.IP) (*api.AdminTeam, error) {
+	cache, err := db.getTeamCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, ok := cache.index.Lookup(ip)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	// Copy the tags so the cached entry can't be modified by the caller
+	team := cache.teams[id]
+	team.Tags = maps.Clone(team.Tags)
+
+	return &team, nil
+}
+
+// getTeamCache returns the team cache, rebuilding it from the database if needed.
+func (db *DB) getTeamCache(ctx context.Context) (*teamCache, error) {
+	db.teamCacheLock.Lock()
+	defer db.teamCacheLock.Unlock()
+
+	if db.teamCache != nil {
+		return db.teamCache, nil
+	}
+
 	// Get all the teams
 	teams, err := db.GetTeams(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	var resp *api.AdminTeam
+	cache := teamCache{
+		index: subnets.NewIndex(),
+		teams: map[int64]api.AdminTeam{},
+	}
 
 	for _, team := range teams {
-		if team.Subnets == "" {
+		cache.teams[team.ID] = team
+
+		teamSubnets, err := subnets.Parse(team.Subnets)
+		if err != nil {
+			db.logger.Error("Bad subnet", log15.Ctx{"error": err, "teamid": team.ID})
+
 			continue
 		}
 
-		subnets := strings.SplitSeq(team.Subnets, ",")
-		for subnet := range subnets {
-			subnet = strings.TrimSpace(subnet)
-
-			_, netSubnet, err := net.ParseCIDR(subnet)
+		for _, subnet := range teamSubnets {
+			err := cache.index.Add(subnet, team.ID)
 			if err != nil {
-				db.logger.Error("Bad subnet", log15.Ctx{"error": err})
-
-				continue
+				db.logger.Error("Bad subnet", log15.Ctx{"error": err, "teamid": team.ID})
 			}
+		}
+	}
 
-			if netSubnet.Contains(ip) {
-				if resp != nil {
-					db.logger.Error("More than one team for client IP", log15.Ctx{"ip": ip.String()})
+	db.teamCache = &cache
 
-					return nil, errors.New("more than one team for client IP")
-				}
+	return db.teamCache, nil
+}
 
-				newTeam := team
-				resp = &newTeam
-			}
+// InvalidateTeamCache forces the team cache to be rebuilt on next use.
+// This must be called whenever the teams are modified (including by other cluster members).
+func (db *DB) InvalidateTeamCache() {
+	db.teamCacheLock.Lock()
+	db.teamCache = nil
+	db.teamCacheLock.Unlock()
+}
+
+// checkTeamSubnets validates the subnets of a team, making sure they don't overlap with those of other teams.
+// The team table is locked until the end of the transaction, so concurrent changes can't introduce an overlap.
+func checkTeamSubnets(ctx context.Context, tx *sql.Tx, id int64, list string) error {
+	teamSubnets, err := subnets.Parse(list)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrTeamSubnets, err)
+	}
+
+	_, err = tx.ExecContext(ctx, "LOCK TABLE team IN SHARE ROW EXCLUSIVE MODE;")
+	if err != nil {
+		return err
+	}
+
+	// Index the other teams, then the new subnets
+	rows, err := tx.QueryContext(ctx, "SELECT id, subnets FROM team WHERE id!=$1;", id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	index := subnets.NewIndex()
+
+	for rows.Next() {
+		otherID := int64(-1)
+		otherList := ""
+
+		err := rows.Scan(&otherID, &otherList)
+		if err != nil {
+			return err
+		}
+
+		otherSubnets, err := subnets.Parse(otherList)
+		if err != nil {
+			continue
+		}
+
+		for _, subnet := range otherSubnets {
+			_ = index.Add(subnet, otherID)
 		}
 	}
 
-	if resp == nil {
-		return nil, sql.ErrNoRows
+	err = rows.Err()
+	if err != nil {
+		return err
 	}
 
-	return resp, nil
+	for _, subnet := range teamSubnets {
+		err := index.Add(subnet, id)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrTeamSubnets, err)
+		}
+	}
+
+	return nil
 }
 
 // CreateTeam adds a new team to the database.
 func (db *DB) CreateTeam(ctx context.Context, team api.AdminTeamPost) (int64, error) {
 	id := int64(-1)
 
+	// Start a transaction
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+
+	// Validate the subnets
+	err = checkTeamSubnets(ctx, tx, id, team.Subnets)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return -1, errRollback
+		}
+
+		return -1, err
+	}
+
 	// Create the database entry
-	err := db.QueryRowContext(ctx, "INSERT INTO team (name, country, website, notes, subnets, tags) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
-		team.Name, team.Country, team.Website, team.Notes, team.Subnets, utils.PackTags(team.Tags)).Scan(&id)
+	err = tx.QueryRowContext(ctx, "INSERT INTO team (name, country, website, notes, subnets, tags, division) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		team.Name, team.Country, team.Website, team.Notes, team.Subnets, utils.PackTags(team.Tags), team.Division).Scan(&id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return -1, errRollback
+		}
+
+		return -1, err
+	}
+
+	// Commit
+	err = tx.Commit()
 	if err != nil {
 		return -1, err
 	}
 
+	db.InvalidateTeamCache()
+
 	return id, nil
 }
 
 // UpdateTeam updates an existing team.
 func (db *DB) UpdateTeam(ctx context.Context, id int64, team api.AdminTeamPut) error {
+	// Start a transaction
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// Validate the subnets
+	err = checkTeamSubnets(ctx, tx, id, team.Subnets)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+
+		return err
+	}
+
 	// Update the database entry
-	result, err := db.ExecContext(ctx, "UPDATE team SET name=$1, country=$2, website=$3, notes=$4, subnets=$5, tags=$6 WHERE id=$7;",
-		team.Name, team.Country, team.Website, team.Notes, team.Subnets, utils.PackTags(team.Tags), id)
+	result, err := tx.ExecContext(ctx, "UPDATE team SET name=$1, country=$2, website=$3, notes=$4, subnets=$5, tags=$6, division=$7 WHERE id=$8;",
+		team.Name, team.Country, team.Website, team.Notes, team.Subnets, utils.PackTags(team.Tags), team.Division, id)
 	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+
 		return err
 	}
 
 	// Check that a change indeed happened
 	count, err := result.RowsAffected()
+	if err != nil || count == 0 {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return sql.ErrNoRows
+	}
+
+	// Commit
+	err = tx.Commit()
 	if err != nil {
 		return err
 	}
 
-	if count == 0 {
-		return sql.ErrNoRows
+	db.InvalidateTeamCache()
+
+	return nil
+}
+
+// SetTeamTag sets a single tag of an existing team, leaving the rest of the team untouched.
+func (db *DB) SetTeamTag(ctx context.Context, id int64, key string, value string) error {
+	// Start a transaction
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// Get the current tags (locking the row until the update)
+	tags := ""
+
+	err = tx.QueryRowContext(ctx, "SELECT COALESCE(tags, '') FROM team WHERE id=$1 FOR UPDATE;", id).Scan(&tags)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+
+		return err
+	}
+
+	teamTags, err := utils.ParseTags(tags)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+
+		return err
 	}
 
+	teamTags[key] = value
+
+	// Update the database entry
+	_, err = tx.ExecContext(ctx, "UPDATE team SET tags=$1 WHERE id=$2;", utils.PackTags(teamTags), id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+
+		return err
+	}
+
+	// Commit
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	db.InvalidateTeamCache()
+
 	return nil
 }
 
@@ -172,6 +377,8 @@ func (db *DB) DeleteTeam(ctx context.Context, id int64) error {
 		return sql.ErrNoRows
 	}
 
+	db.InvalidateTeamCache()
+
 	return nil
 }
 
@@ -211,5 +418,7 @@ func (db *DB) ClearTeams(ctx context.Context) error {
 		return err
 	}
 
+	db.InvalidateTeamCache()
+
 	return nil
 }
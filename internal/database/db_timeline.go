@@ -2,17 +2,23 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/nsec/askgod/api"
 )
 
 // GetTimeline generates the current timeline.
-func (db *DB) GetTimeline(ctx context.Context) ([]api.TimelineEntry, error) {
+func (db *DB) GetTimeline(ctx context.Context, filter ScoreFilter) ([]api.TimelineEntry, error) {
 	// Return a list of score entries
 	resp := []api.TimelineEntry{}
 
+	entries, err := db.filteredScoreEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
 	// Query all the scores from the database
-	rows, err := db.QueryContext(ctx, "SELECT team.id, team.country, team.name, team.website, score.value, score.submit_time FROM score LEFT JOIN team ON team.id=score.teamid ORDER BY team.id ASC, score.submit_time ASC;")
+	rows, err := db.QueryContext(ctx, "SELECT team.id, team.country, team.name, team.website, team.division, score.value, score.submit_time FROM "+entries+" LEFT JOIN team ON team.id=score.teamid LEFT JOIN flag ON flag.id=score.flagid WHERE "+scoreFilterCondition+" AND ($4 = '' OR team.division=$4) ORDER BY team.id ASC, score.submit_time ASC;", scoreFilterArgs(filter)...)
 	if err != nil {
 		return nil, err
 	}
@@ -26,7 +32,7 @@ func (db *DB) GetTimeline(ctx context.Context) ([]api.TimelineEntry, error) {
 		rowTeam := api.Team{}
 		rowScore := api.TimelineEntryScore{}
 
-		err := rows.Scan(&rowTeam.ID, &rowTeam.Country, &rowTeam.Name, &rowTeam.Website, &rowScore.Value, &rowScore.SubmitTime)
+		err := rows.Scan(&rowTeam.ID, &rowTeam.Country, &rowTeam.Name, &rowTeam.Website, &rowTeam.Division, &rowScore.Value, &rowScore.SubmitTime)
 		if err != nil {
 			return nil, err
 		}
@@ -61,3 +67,37 @@ func (db *DB) GetTimeline(ctx context.Context) ([]api.TimelineEntry, error) {
 
 	return resp, nil
 }
+
+// GetScoreEvents retrieves the score entries submitted at or after the given time, in submission order.
+func (db *DB) GetScoreEvents(ctx context.Context, since time.Time) ([]ScoreEvent, error) {
+	// Return a list of score events
+	resp := []ScoreEvent{}
+
+	// Query the entries along with the running total of their team
+	rows, err := db.QueryContext(ctx, "SELECT teamid, COALESCE(flagid, 0), type, value, total, CASE WHEN type=$2 THEN (SELECT COUNT(*) FROM score AS other WHERE other.flagid=score.flagid AND other.type=$3 AND other.id <= (SELECT solve.id FROM score AS solve WHERE solve.teamid=score.teamid AND solve.flagid=score.flagid AND solve.type=$3)) ELSE 0 END, submit_time FROM (SELECT score.id, score.teamid, score.flagid, score.type, score.value, score.submit_time, SUM(score.value) OVER (PARTITION BY score.teamid ORDER BY score.submit_time, score.id) AS total FROM "+scoreEntries+") AS score WHERE submit_time >= $1 ORDER BY submit_time ASC, id ASC;",
+		since, api.ScoreTypeBonus, api.ScoreTypeSolve)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Iterate through the results
+	for rows.Next() {
+		row := ScoreEvent{}
+
+		err := rows.Scan(&row.TeamID, &row.FlagID, &row.Type, &row.Value, &row.Total, &row.Rank, &row.SubmitTime)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = append(resp, row)
+	}
+
+	// Check for any error that might have happened
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"time"
+
+	"github.com/nsec/askgod/api"
+)
+
+// tokenUsageInterval is how often the last use of a token (or admin key) gets recorded (rather than on every request).
+const tokenUsageInterval = time.Minute
+
+// hashToken returns the form in which API tokens are stored.
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// generateToken returns a new random API token.
+func generateToken() (string, error) {
+	secret := make([]byte, 32)
+
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+// GetTeamTokens retrieves all the tokens of a team from the database (without the tokens themselves).
+func (db *DB) GetTeamTokens(ctx context.Context, teamid int64) ([]api.AdminTeamToken, error) {
+	// Return a list of tokens
+	resp := []api.AdminTeamToken{}
+
+	// Query all the tokens from the database
+	rows, err := db.QueryContext(ctx, "SELECT id, teamid, description, created_at, last_used FROM team_token WHERE teamid=$1 ORDER BY id ASC;", teamid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Iterate through the results
+	for rows.Next() {
+		row := api.AdminTeamToken{}
+		lastUsed := sql.NullTime{}
+
+		err := rows.Scan(&row.ID, &row.TeamID, &row.Description, &row.CreatedAt, &lastUsed)
+		if err != nil {
+			return nil, err
+		}
+
+		row.LastUsed = lastUsed.Time
+
+		resp = append(resp, row)
+	}
+
+	// Check for any error that might have happened
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// CreateTeamToken issues a new random token for the team.
+// The token is only returned here, the database only keeping its hash.
+func (db *DB) CreateTeamToken(ctx context.Context, teamid int64, token api.AdminTeamTokenPost) (*api.AdminTeamToken, error) {
+	// Generate the token
+	secret, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := api.AdminTeamToken{
+		AdminTeamTokenPost: token,
+		TeamID:             teamid,
+		CreatedAt:          time.Now(),
+		Token:              secret,
+	}
+
+	// Create the database entry
+	err = db.QueryRowContext(ctx, "INSERT INTO team_token (teamid, hash, description, created_at) VALUES ($1, $2, $3, $4) RETURNING id",
+		teamid, hashToken(resp.Token), token.Description, resp.CreatedAt).Scan(&resp.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// DeleteTeamToken revokes a single token of a team.
+func (db *DB) DeleteTeamToken(ctx context.Context, teamid int64, id int64) error {
+	// Delete the database entry
+	result, err := db.ExecContext(ctx, "DELETE FROM team_token WHERE teamid=$1 AND id=$2;", teamid, id)
+	if err != nil {
+		return err
+	}
+
+	// Check that a change indeed happened
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ClearTeamTokens revokes all the tokens of a team.
+func (db *DB) ClearTeamTokens(ctx context.Context, teamid int64) error {
+	_, err := db.ExecContext(ctx, "DELETE FROM team_token WHERE teamid=$1;", teamid)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetTeamForToken retrieves the team owning the provided token.
+func (db *DB) GetTeamForToken(ctx context.Context, token string) (*api.AdminTeam, error) {
+	id := int64(-1)
+	teamid := int64(-1)
+	lastUsed := sql.NullTime{}
+
+	// Find the token
+	err := db.QueryRowContext(ctx, "SELECT id, teamid, last_used FROM team_token WHERE hash=$1;", hashToken(token)).Scan(&id, &teamid, &lastUsed)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record its use
+	now := time.Now()
+	if !lastUsed.Valid || now.Sub(lastUsed.Time) >= tokenUsageInterval {
+		_, err = db.ExecContext(ctx, "UPDATE team_token SET last_used=$1 WHERE id=$2;", now, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return db.GetTeam(ctx, teamid)
+}
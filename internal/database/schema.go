@@ -13,6 +13,13 @@ CREATE TABLE IF NOT EXISTS flag (
     return_string VARCHAR,
     description VARCHAR,
     tags VARCHAR,
+    initial_value INTEGER NOT NULL DEFAULT 0,
+    minimum_value INTEGER NOT NULL DEFAULT 0,
+    decay INTEGER NOT NULL DEFAULT 0,
+    requires VARCHAR NOT NULL DEFAULT '',
+    valid_from TIMESTAMP WITH TIME ZONE,
+    valid_until TIMESTAMP WITH TIME ZONE,
+    trap BOOLEAN NOT NULL DEFAULT false,
     UNIQUE(flag)
 );
 
@@ -23,7 +30,8 @@ CREATE TABLE IF NOT EXISTS team (
     website VARCHAR(255),
     notes VARCHAR,
     subnets VARCHAR,
-    tags VARCHAR
+    tags VARCHAR,
+    division VARCHAR NOT NULL DEFAULT ''
 );
 
 CREATE TABLE IF NOT EXISTS score (
@@ -34,9 +42,116 @@ CREATE TABLE IF NOT EXISTS score (
     submit_time TIMESTAMP WITH TIME ZONE,
     notes VARCHAR,
     source VARCHAR NOT NULL DEFAULT 'unknown',
+    type VARCHAR NOT NULL DEFAULT 'solve',
     FOREIGN KEY (teamid) REFERENCES team (id) ON DELETE CASCADE,
+    FOREIGN KEY (flagid) REFERENCES flag (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS score_teamid_flagid_type_key ON score (teamid, flagid, type) WHERE type != 'hint';
+
+CREATE TABLE IF NOT EXISTS flag_variant (
+    id SERIAL PRIMARY KEY,
+    flagid INTEGER NOT NULL,
+    teamid INTEGER NOT NULL,
+    flag VARCHAR NOT NULL,
     FOREIGN KEY (flagid) REFERENCES flag (id) ON DELETE CASCADE,
-    UNIQUE(teamid, flagid)
+    FOREIGN KEY (teamid) REFERENCES team (id) ON DELETE CASCADE,
+    UNIQUE(flagid, teamid)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS flag_variant_lower_flag_key ON flag_variant (LOWER(flag));
+
+CREATE TABLE IF NOT EXISTS hint (
+    id SERIAL PRIMARY KEY,
+    flagid INTEGER NOT NULL,
+    description VARCHAR,
+    text VARCHAR,
+    cost INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (flagid) REFERENCES flag (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS hint_unlock (
+    id SERIAL PRIMARY KEY,
+    hintid INTEGER NOT NULL,
+    teamid INTEGER NOT NULL,
+    scoreid INTEGER NOT NULL,
+    FOREIGN KEY (hintid) REFERENCES hint (id) ON DELETE CASCADE,
+    FOREIGN KEY (teamid) REFERENCES team (id) ON DELETE CASCADE,
+    FOREIGN KEY (scoreid) REFERENCES score (id) ON DELETE CASCADE,
+    UNIQUE(hintid, teamid)
+);
+
+CREATE TABLE IF NOT EXISTS adjustment (
+    id SERIAL PRIMARY KEY,
+    teamid INTEGER NOT NULL,
+    value INTEGER NOT NULL,
+    reason VARCHAR NOT NULL DEFAULT '',
+    author VARCHAR NOT NULL DEFAULT '',
+    submit_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY (teamid) REFERENCES team (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS submission (
+    id SERIAL PRIMARY KEY,
+    teamid INTEGER NOT NULL,
+    flagid INTEGER,
+    ip VARCHAR NOT NULL,
+    input VARCHAR NOT NULL,
+    outcome VARCHAR NOT NULL,
+    source VARCHAR NOT NULL DEFAULT '',
+    submit_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY (teamid) REFERENCES team (id) ON DELETE CASCADE,
+    FOREIGN KEY (flagid) REFERENCES flag (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS submission_teamid_idx ON submission (teamid);
+CREATE INDEX IF NOT EXISTS submission_submit_time_idx ON submission (submit_time);
+
+CREATE TABLE IF NOT EXISTS team_token (
+    id SERIAL PRIMARY KEY,
+    teamid INTEGER NOT NULL,
+    hash VARCHAR NOT NULL,
+    description VARCHAR NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_used TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (teamid) REFERENCES team (id) ON DELETE CASCADE,
+    UNIQUE(hash)
+);
+
+CREATE TABLE IF NOT EXISTS admin_key (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR NOT NULL,
+    role VARCHAR NOT NULL,
+    hash VARCHAR NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_used TIMESTAMP WITH TIME ZONE,
+    UNIQUE(name),
+    UNIQUE(hash)
+);
+
+CREATE TABLE IF NOT EXISTS audit (
+    id SERIAL PRIMARY KEY,
+    principal VARCHAR NOT NULL,
+    ip VARCHAR NOT NULL,
+    method VARCHAR NOT NULL,
+    url VARCHAR NOT NULL,
+    status INTEGER NOT NULL,
+    before JSONB,
+    after JSONB,
+    submit_time TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_submit_time_idx ON audit (submit_time);
+
+CREATE OR REPLACE RULE audit_no_update AS ON UPDATE TO audit DO INSTEAD NOTHING;
+CREATE OR REPLACE RULE audit_no_delete AS ON DELETE TO audit DO INSTEAD NOTHING;
+
+CREATE TABLE IF NOT EXISTS ratelimit (
+    key VARCHAR PRIMARY KEY,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    lockouts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE
 );
 
 CREATE TABLE IF NOT EXISTS schema (
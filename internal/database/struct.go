@@ -2,8 +2,13 @@ package database
 
 import (
 	"database/sql"
+	"sync"
+	"time"
 
 	"github.com/inconshreveable/log15"
+
+	"github.com/nsec/askgod/api"
+	"github.com/nsec/askgod/internal/subnets"
 )
 
 // DB represents the Askgod database.
@@ -11,4 +16,75 @@ type DB struct {
 	*sql.DB
 
 	logger log15.Logger
+
+	teamCache     *teamCache
+	teamCacheLock sync.Mutex
+}
+
+// teamCache holds the teams indexed by subnet, to quickly identify them by IP.
+type teamCache struct {
+	index *subnets.Index
+	teams map[int64]api.AdminTeam
+}
+
+// ScoreEvent is a score entry (or manual adjustment) as sent in timeline events.
+//
+// Total is the score of the team including the entry. Rank is the solve rank
+// of the team on the flag for first solvers bonuses.
+type ScoreEvent struct {
+	TeamID     int64
+	FlagID     int64
+	Type       string
+	Value      int64
+	Total      int64
+	Rank       int64
+	SubmitTime time.Time
+}
+
+// ScoreFilter restricts the score entries used to build the scoreboard and timeline.
+//
+// Before excludes entries submitted at or after the given time (unless zero),
+// with the exception of those belonging to TeamID.
+//
+// At excludes entries submitted after the given time (unless zero).
+//
+// Tag restricts the entries to flags carrying the given "key:value" tag.
+//
+// Division restricts the entries to teams in the given division.
+//
+// DynamicValues values the solves of dynamic flags from the number of solves
+// let through by Before and At, rather than from all their solves so far.
+type ScoreFilter struct {
+	Before        time.Time
+	TeamID        int64
+	At            time.Time
+	Tag           string
+	Division      string
+	DynamicValues bool
+}
+
+// SubmissionFilter restricts the submission attempts returned by GetSubmissions.
+//
+// Zero values don't restrict anything. Limit only keeps the most recent entries.
+type SubmissionFilter struct {
+	TeamID  int64
+	FlagID  int64
+	IP      string
+	Outcome string
+	Source  string
+	Since   time.Time
+	Until   time.Time
+	Limit   int64
+}
+
+// AuditFilter restricts the audit entries returned by GetAuditEntries.
+//
+// Zero values don't restrict anything. Target matches the start of the URL
+// and Limit only keeps the most recent entries.
+type AuditFilter struct {
+	Principal string
+	Target    string
+	Since     time.Time
+	Until     time.Time
+	Limit     int64
 }
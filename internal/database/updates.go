@@ -11,6 +11,22 @@ var dbUpdates = []dbUpdate{
 	{version: 1, run: dbUpdateFromV0},
 	{version: 2, run: dbUpdateFromV1},
 	{version: 3, run: dbUpdateFromV2},
+	{version: 4, run: dbUpdateFromV3},
+	{version: 5, run: dbUpdateFromV4},
+	{version: 6, run: dbUpdateFromV5},
+	{version: 7, run: dbUpdateFromV6},
+	{version: 8, run: dbUpdateFromV7},
+	{version: 9, run: dbUpdateFromV8},
+	{version: 10, run: dbUpdateFromV9},
+	{version: 11, run: dbUpdateFromV10},
+	{version: 12, run: dbUpdateFromV11},
+	{version: 13, run: dbUpdateFromV12},
+	{version: 14, run: dbUpdateFromV13},
+	{version: 15, run: dbUpdateFromV14},
+	{version: 16, run: dbUpdateFromV15},
+	{version: 17, run: dbUpdateFromV16},
+	{version: 18, run: dbUpdateFromV17},
+	{version: 19, run: dbUpdateFromV18},
 }
 
 type dbUpdate struct {
@@ -58,3 +74,222 @@ func dbUpdateFromV2(ctx context.Context, _, _ int, db *DB) error {
 
 	return err
 }
+
+func dbUpdateFromV3(ctx context.Context, _, _ int, db *DB) error {
+	_, err := db.ExecContext(ctx, `
+ALTER TABLE flag ADD COLUMN initial_value INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE flag ADD COLUMN minimum_value INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE flag ADD COLUMN decay INTEGER NOT NULL DEFAULT 0;
+	`)
+
+	return err
+}
+
+func dbUpdateFromV4(ctx context.Context, _, _ int, db *DB) error {
+	_, err := db.ExecContext(ctx, `
+ALTER TABLE score ADD COLUMN type VARCHAR NOT NULL DEFAULT 'solve';
+ALTER TABLE score DROP CONSTRAINT IF EXISTS score_teamid_flagid_key;
+ALTER TABLE score ADD CONSTRAINT score_teamid_flagid_type_key UNIQUE (teamid, flagid, type);
+	`)
+
+	return err
+}
+
+func dbUpdateFromV5(ctx context.Context, _, _ int, db *DB) error {
+	_, err := db.ExecContext(ctx, "ALTER TABLE flag ADD COLUMN requires VARCHAR NOT NULL DEFAULT '';")
+
+	return err
+}
+
+func dbUpdateFromV6(ctx context.Context, _, _ int, db *DB) error {
+	_, err := db.ExecContext(ctx, `
+ALTER TABLE flag ADD COLUMN valid_from TIMESTAMP WITH TIME ZONE;
+ALTER TABLE flag ADD COLUMN valid_until TIMESTAMP WITH TIME ZONE;
+	`)
+
+	return err
+}
+
+func dbUpdateFromV7(ctx context.Context, _, _ int, db *DB) error {
+	_, err := db.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS flag_variant (
+    id SERIAL PRIMARY KEY,
+    flagid INTEGER NOT NULL,
+    teamid INTEGER NOT NULL,
+    flag VARCHAR NOT NULL,
+    FOREIGN KEY (flagid) REFERENCES flag (id) ON DELETE CASCADE,
+    FOREIGN KEY (teamid) REFERENCES team (id) ON DELETE CASCADE,
+    UNIQUE(flag),
+    UNIQUE(flagid, teamid)
+);
+	`)
+
+	return err
+}
+
+func dbUpdateFromV8(ctx context.Context, _, _ int, db *DB) error {
+	_, err := db.ExecContext(ctx, `
+ALTER TABLE score DROP CONSTRAINT IF EXISTS score_teamid_flagid_type_key;
+CREATE UNIQUE INDEX IF NOT EXISTS score_teamid_flagid_type_key ON score (teamid, flagid, type) WHERE type != 'hint';
+
+CREATE TABLE IF NOT EXISTS hint (
+    id SERIAL PRIMARY KEY,
+    flagid INTEGER NOT NULL,
+    description VARCHAR,
+    text VARCHAR,
+    cost INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (flagid) REFERENCES flag (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS hint_unlock (
+    id SERIAL PRIMARY KEY,
+    hintid INTEGER NOT NULL,
+    teamid INTEGER NOT NULL,
+    scoreid INTEGER NOT NULL,
+    FOREIGN KEY (hintid) REFERENCES hint (id) ON DELETE CASCADE,
+    FOREIGN KEY (teamid) REFERENCES team (id) ON DELETE CASCADE,
+    FOREIGN KEY (scoreid) REFERENCES score (id) ON DELETE CASCADE,
+    UNIQUE(hintid, teamid)
+);
+	`)
+
+	return err
+}
+
+func dbUpdateFromV9(ctx context.Context, _, _ int, db *DB) error {
+	_, err := db.ExecContext(ctx, "ALTER TABLE flag ADD COLUMN trap BOOLEAN NOT NULL DEFAULT false;")
+
+	return err
+}
+
+func dbUpdateFromV10(ctx context.Context, _, _ int, db *DB) error {
+	_, err := db.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS ratelimit (
+    key VARCHAR PRIMARY KEY,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    lockouts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE
+);
+	`)
+
+	return err
+}
+
+func dbUpdateFromV11(ctx context.Context, _, _ int, db *DB) error {
+	_, err := db.ExecContext(ctx, "ALTER TABLE team ADD COLUMN division VARCHAR NOT NULL DEFAULT '';")
+
+	return err
+}
+
+func dbUpdateFromV12(ctx context.Context, _, _ int, db *DB) error {
+	_, err := db.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS adjustment (
+    id SERIAL PRIMARY KEY,
+    teamid INTEGER NOT NULL,
+    value INTEGER NOT NULL,
+    reason VARCHAR NOT NULL DEFAULT '',
+    author VARCHAR NOT NULL DEFAULT '',
+    submit_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY (teamid) REFERENCES team (id) ON DELETE CASCADE
+);
+	`)
+
+	return err
+}
+
+func dbUpdateFromV13(ctx context.Context, _, _ int, db *DB) error {
+	_, err := db.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS submission (
+    id SERIAL PRIMARY KEY,
+    teamid INTEGER NOT NULL,
+    flagid INTEGER,
+    ip VARCHAR NOT NULL,
+    input VARCHAR NOT NULL,
+    outcome VARCHAR NOT NULL,
+    source VARCHAR NOT NULL DEFAULT '',
+    submit_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY (teamid) REFERENCES team (id) ON DELETE CASCADE,
+    FOREIGN KEY (flagid) REFERENCES flag (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS submission_teamid_idx ON submission (teamid);
+CREATE INDEX IF NOT EXISTS submission_submit_time_idx ON submission (submit_time);
+	`)
+
+	return err
+}
+
+func dbUpdateFromV14(ctx context.Context, _, _ int, db *DB) error {
+	_, err := db.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS team_token (
+    id SERIAL PRIMARY KEY,
+    teamid INTEGER NOT NULL,
+    hash VARCHAR NOT NULL,
+    description VARCHAR NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_used TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (teamid) REFERENCES team (id) ON DELETE CASCADE,
+    UNIQUE(hash)
+);
+	`)
+
+	return err
+}
+
+func dbUpdateFromV15(ctx context.Context, _, _ int, db *DB) error {
+	_, err := db.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS admin_key (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR NOT NULL,
+    role VARCHAR NOT NULL,
+    hash VARCHAR NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_used TIMESTAMP WITH TIME ZONE,
+    UNIQUE(name),
+    UNIQUE(hash)
+);
+	`)
+
+	return err
+}
+
+func dbUpdateFromV16(ctx context.Context, _, _ int, db *DB) error {
+	_, err := db.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS audit (
+    id SERIAL PRIMARY KEY,
+    principal VARCHAR NOT NULL,
+    ip VARCHAR NOT NULL,
+    method VARCHAR NOT NULL,
+    url VARCHAR NOT NULL,
+    before JSONB,
+    after JSONB,
+    submit_time TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_submit_time_idx ON audit (submit_time);
+
+CREATE OR REPLACE RULE audit_no_update AS ON UPDATE TO audit DO INSTEAD NOTHING;
+CREATE OR REPLACE RULE audit_no_delete AS ON DELETE TO audit DO INSTEAD NOTHING;
+	`)
+
+	return err
+}
+
+func dbUpdateFromV17(ctx context.Context, _, _ int, db *DB) error {
+	_, err := db.ExecContext(ctx, `
+ALTER TABLE flag_variant DROP CONSTRAINT IF EXISTS flag_variant_flag_key;
+CREATE UNIQUE INDEX IF NOT EXISTS flag_variant_lower_flag_key ON flag_variant (LOWER(flag));
+	`)
+
+	return err
+}
+
+func dbUpdateFromV18(ctx context.Context, _, _ int, db *DB) error {
+	_, err := db.ExecContext(ctx, `
+ALTER TABLE audit ADD COLUMN IF NOT EXISTS status INTEGER NOT NULL DEFAULT 200;
+ALTER TABLE audit ALTER COLUMN status DROP DEFAULT;
+	`)
+
+	return err
+}
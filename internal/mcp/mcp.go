@@ -107,6 +107,27 @@ func (m *MCP) handleToolsList(w http.ResponseWriter, id any) {
 					Required: []string{"flag"},
 				},
 			},
+			{
+				Name:        "list_hints",
+				Description: "List the hints available to your team, along with their cost in points and the text of the ones already unlocked.",
+				InputSchema: ToolInputSchema{
+					Type: "object",
+				},
+			},
+			{
+				Name:        "unlock_hint",
+				Description: "Unlock a hint for your team. The cost of the hint is deducted from your team's score.",
+				InputSchema: ToolInputSchema{
+					Type: "object",
+					Properties: map[string]any{
+						"id": map[string]any{
+							"type":        "integer",
+							"description": "The ID of the hint to unlock",
+						},
+					},
+					Required: []string{"id"},
+				},
+			},
 		},
 	}
 
@@ -128,6 +149,10 @@ func (m *MCP) handleToolsCall(w http.ResponseWriter, r *http.Request, id any, pa
 	switch p.Name {
 	case "submit_flag":
 		result = m.submitFlag(r, p.Arguments)
+	case "list_hints":
+		result = m.listHints(r)
+	case "unlock_hint":
+		result = m.unlockHint(r, p.Arguments)
 	default:
 		m.writeError(w, id, -32602, "Unknown tool: "+p.Name)
 
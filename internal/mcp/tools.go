@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 
 	"github.com/nsec/askgod/api"
@@ -22,9 +24,7 @@ func (m *MCP) submitFlag(r *http.Request, args map[string]any) CallToolResult {
 		return errorResult("Internal server error")
 	}
 
-	req, _ := http.NewRequestWithContext(r.Context(), http.MethodPost, "/1.0/team/flags", bytes.NewReader(body))
-	req.RemoteAddr = r.RemoteAddr
-	req.Header.Set("Content-Type", "application/json")
+	req := teamRequest(r, http.MethodPost, "/1.0/team/flags", body)
 
 	rec := httptest.NewRecorder()
 	m.handler.ServeHTTP(rec, req)
@@ -41,6 +41,9 @@ func (m *MCP) submitFlag(r *http.Request, args map[string]any) CallToolResult {
 	}
 
 	msg := fmt.Sprintf("Correct flag! +%d points\n", result.Value)
+	if result.Trap {
+		msg = fmt.Sprintf("You shouldn't have sent that! %d points\n", result.Value)
+	}
 	if result.ReturnString != "" {
 		msg += fmt.Sprintf("Return message: %s\n", result.ReturnString)
 	}
@@ -48,6 +51,91 @@ func (m *MCP) submitFlag(r *http.Request, args map[string]any) CallToolResult {
 	return CallToolResult{Content: []Content{{Type: "text", Text: msg}}}
 }
 
+func (m *MCP) listHints(r *http.Request) CallToolResult {
+	req := teamRequest(r, http.MethodGet, "/1.0/team/hints", nil)
+
+	rec := httptest.NewRecorder()
+	m.handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		return errorResult(strings.TrimSpace(rec.Body.String()))
+	}
+
+	var hints []api.Hint
+
+	err := json.NewDecoder(rec.Body).Decode(&hints)
+	if err != nil {
+		return errorResult("Internal server error")
+	}
+
+	if len(hints) == 0 {
+		return CallToolResult{Content: []Content{{Type: "text", Text: "No hints are available\n"}}}
+	}
+
+	msg := ""
+	for _, hint := range hints {
+		msg += fmt.Sprintf("Hint %d (flag %d, cost %d points): %s\n", hint.ID, hint.FlagID, hint.Cost, hint.Description)
+		if hint.Unlocked {
+			msg += fmt.Sprintf("  Unlocked: %s\n", hint.Text)
+		}
+	}
+
+	return CallToolResult{Content: []Content{{Type: "text", Text: msg}}}
+}
+
+func (m *MCP) unlockHint(r *http.Request, args map[string]any) CallToolResult {
+	hintID, ok := args["id"].(float64)
+	if !ok || hintID < 1 || hintID != float64(int64(hintID)) {
+		return errorResult("Missing or invalid 'id' argument")
+	}
+
+	body, err := json.Marshal(api.HintPost{Source: api.SourceMCP})
+	if err != nil {
+		return errorResult("Internal server error")
+	}
+
+	req := teamRequest(r, http.MethodPost, fmt.Sprintf("/1.0/team/hints/%d", int64(hintID)), body)
+
+	rec := httptest.NewRecorder()
+	m.handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		return errorResult(strings.TrimSpace(rec.Body.String()))
+	}
+
+	var result api.Hint
+
+	err = json.NewDecoder(rec.Body).Decode(&result)
+	if err != nil {
+		return errorResult("Internal server error")
+	}
+
+	msg := fmt.Sprintf("Hint unlocked! -%d points\nHint: %s\n", result.Cost, result.Text)
+
+	return CallToolResult{Content: []Content{{Type: "text", Text: msg}}}
+}
+
+// teamRequest builds a request to the team API on behalf of the MCP client.
+// The original request is cloned so the team is identified the same way (address, headers and TLS state).
+func teamRequest(r *http.Request, method string, path string, body []byte) *http.Request {
+	req := r.Clone(r.Context())
+	req.Method = method
+	req.URL = &url.URL{Path: path}
+	req.RequestURI = ""
+	req.Body = http.NoBody
+	req.GetBody = nil
+	req.ContentLength = 0
+	req.Header.Del("Content-Type")
+
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req
+}
+
 func errorResult(msg string) CallToolResult {
 	return CallToolResult{Content: []Content{{Type: "text", Text: msg}}, IsError: true}
 }
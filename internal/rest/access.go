@@ -1,11 +1,19 @@
 package rest
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
 	"net"
 	"net/http"
 	"slices"
+	"strings"
 
 	"github.com/inconshreveable/log15"
+
+	"github.com/nsec/askgod/api"
 )
 
 func (r *rest) getIP(request *http.Request) (*net.IP, error) {
@@ -24,81 +32,352 @@ func (r *rest) getIP(request *http.Request) (*net.IP, error) {
 		return nil, err
 	}
 
+	// Look for the original client behind trusted proxies
+	if r.isTrustedProxy(ip) {
+		forwarded := getForwardedFor(request, r.config.Daemon.ForwardedHeader)
+		for i := len(forwarded) - 1; i >= 0; i-- {
+			// Don't fall back to the proxy's own address for unknown or obfuscated clients
+			forwardedIP := net.ParseIP(forwarded[i])
+			if forwardedIP == nil {
+				r.logger.Warn("Unable to parse forwarded client IP", log15.Ctx{"ip": forwarded[i], "proxy": ip.String()})
+
+				return nil, fmt.Errorf("invalid forwarded client IP %q", forwarded[i])
+			}
+
+			ip = forwardedIP
+			if !r.isTrustedProxy(ip) {
+				break
+			}
+		}
+	}
+
 	return &ip, nil
 }
 
+// isTrustedProxy checks whether the IP belongs to one of the trusted proxies.
+func (r *rest) isTrustedProxy(ip net.IP) bool {
+	return r.acl.Load().trustedProxies.Contains(ip)
+}
+
+// getForwardedFor returns the client addresses recorded by proxies in the given header, from the original client to the last proxy.
+// Only the header set by the proxies may be used, the other one being passed through from the client.
+func getForwardedFor(request *http.Request, header string) []string {
+	addresses := []string{}
+
+	if http.CanonicalHeaderKey(header) == "Forwarded" {
+		forwarded := request.Header.Values("Forwarded")
+		for _, element := range strings.Split(strings.Join(forwarded, ","), ",") {
+			for param := range strings.SplitSeq(element, ";") {
+				key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(key, "for") {
+					continue
+				}
+
+				addresses = append(addresses, stripPort(strings.Trim(value, "\"")))
+			}
+		}
+
+		return addresses
+	}
+
+	for _, value := range request.Header.Values("X-Forwarded-For") {
+		for entry := range strings.SplitSeq(value, ",") {
+			addresses = append(addresses, stripPort(strings.TrimSpace(entry)))
+		}
+	}
+
+	return addresses
+}
+
+// stripPort removes the optional port (and IPv6 brackets) from a forwarded address.
+func stripPort(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err == nil {
+		return host
+	}
+
+	return strings.Trim(address, "[]")
+}
+
 func (r *rest) hasAccess(level string, request *http.Request) bool {
-	// Check for cluster peers
-	if len(r.config.Daemon.ClusterPeers) != 0 && r.isPeer(request) {
+	// Check if admin (of any role)
+	_, role := r.getAdminPrincipal(request)
+	if role != "" {
 		return true
 	}
 
+	if level == "admin" {
+		return false
+	}
+
 	// Get the IP
 	ip, err := r.getIP(request)
 	if err != nil {
 		return false
 	}
 
-	// Check if admin
-	for _, entry := range r.config.Subnets.Admins {
-		_, subnet, err := net.ParseCIDR(entry)
+	// Check for a team certificate
+	cert := r.getClientCertificate(request)
+	if cert != nil && cert.Role == api.ClientCertificateRoleTeam {
+		return true
+	}
+
+	// Check for a team token
+	if getRequestToken(request) != "" {
+		_, err := r.getTokenTeam(request)
 		if err != nil {
-			r.logger.Error("Unable to parse configured subnet", log15.Ctx{"subnet": entry, "error": err})
+			r.logger.Warn("Invalid team token", log15.Ctx{"method": request.Method, "url": request.URL.Path, "client": ip.String()})
 
-			continue
+			return false
 		}
 
-		if subnet.Contains(*ip) {
-			return true
-		}
+		return true
 	}
 
-	if level == "admin" {
+	// Check if team
+	if r.acl.Load().teams.Contains(*ip) {
+		return true
+	}
+
+	if level == "team" {
 		return false
 	}
 
-	// Check if team
-	for _, entry := range r.config.Subnets.Teams {
-		_, subnet, err := net.ParseCIDR(entry)
-		if err != nil {
-			r.logger.Error("Unable to parse configured subnet", log15.Ctx{"subnet": entry, "error": err})
+	// Check if guest
+	if r.acl.Load().guests.Contains(*ip) {
+		return true
+	}
 
-			continue
-		}
+	r.logger.Warn("Unauthorized access", log15.Ctx{"method": request.Method, "url": getLogURL(request), "client": ip.String()})
 
-		if subnet.Contains(*ip) {
-			return true
-		}
+	return false
+}
+
+func (r *rest) isPeer(request *http.Request) bool {
+	// Check for a peer certificate
+	cert := r.getClientCertificate(request)
+	if cert != nil && cert.Role == api.ClientCertificateRolePeer {
+		return true
 	}
 
-	if level == "team" {
+	ip, err := r.getIP(request)
+	if err != nil {
 		return false
 	}
 
-	// Check if guest
-	for _, entry := range r.config.Subnets.Guests {
-		_, subnet, err := net.ParseCIDR(entry)
-		if err != nil {
-			r.logger.Error("Unable to parse configured subnet", log15.Ctx{"subnet": entry, "error": err})
+	return slices.Contains(clusterPeers, ip.String())
+}
 
-			continue
-		}
+// getAdminPrincipal returns the principal admin actions of the request are attributed to, along with its role.
+// Both are empty if the request doesn't come from an admin.
+func (r *rest) getAdminPrincipal(request *http.Request) (string, string) {
+	identity := getRequestIdentity(request)
+	if !identity.adminResolved {
+		identity.principal, identity.role = r.resolveAdminPrincipal(request)
+		identity.adminResolved = true
+	}
+
+	return identity.principal, identity.role
+}
 
-		if subnet.Contains(*ip) {
-			return true
+// resolveAdminPrincipal identifies the admin making the request (see getAdminPrincipal).
+func (r *rest) resolveAdminPrincipal(request *http.Request) (string, string) {
+	// Get the IP
+	ip, err := r.getIP(request)
+	if err != nil {
+		return "", ""
+	}
+
+	// Check for client certificates
+	cert := r.getClientCertificate(request)
+	if cert != nil && cert.Role == api.ClientCertificateRolePeer {
+		return "peer:" + cert.Name, api.AdminRoleAdmin
+	} else if cert != nil && cert.Role != api.ClientCertificateRoleTeam {
+		return "cert:" + cert.Name, cert.Role
+	}
+
+	// Check for cluster peers
+	if len(r.config.Daemon.ClusterPeers) != 0 && r.isPeer(request) {
+		return "peer:" + ip.String(), api.AdminRoleAdmin
+	}
+
+	// Check for an admin key
+	token := getRequestToken(request)
+	if token != "" {
+		key, err := r.db.GetAdminKeyForToken(request.Context(), token)
+		if err == nil {
+			return "key:" + key.Name, key.Role
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			r.logger.Error("Failed to check the admin key", log15.Ctx{"error": err})
 		}
 	}
 
-	r.logger.Warn("Unauthorized access", log15.Ctx{"method": request.Method, "url": request.URL, "client": ip.String()})
+	// Check for admin subnets
+	if r.acl.Load().admins.Contains(*ip) {
+		return "subnet:" + ip.String(), api.AdminRoleAdmin
+	}
 
-	return false
+	return "", ""
 }
 
-func (r *rest) isPeer(request *http.Request) bool {
+// adminRoleScopes maps the limited admin roles to the endpoint scope they may modify.
+var adminRoleScopes = map[string]string{
+	api.AdminRoleFlagManager:  "flags",
+	api.AdminRoleScoreManager: "scores",
+}
+
+// adminAllowed checks whether an admin role may use the given method on an endpoint scope.
+// Only full admins have access to endpoints without a scope.
+func adminAllowed(role string, scope string, method string) bool {
+	if role == api.AdminRoleAdmin {
+		return true
+	}
+
+	if role == "" || scope == "" {
+		return false
+	}
+
+	// All admin roles get read-only access
+	if method == http.MethodGet || method == http.MethodOptions {
+		return true
+	}
+
+	return adminRoleScopes[role] == scope
+}
+
+// getRequestToken returns the API token (team token or admin key) provided with the request, if any.
+// The token is normally sent as a bearer token but may also be passed as
+// the token parameter for websockets (browsers can't set their headers).
+func getRequestToken(request *http.Request) string {
+	token, ok := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+	if ok {
+		return strings.TrimSpace(token)
+	}
+
+	return request.URL.Query().Get("token")
+}
+
+// requestIdentityKey is the context key under which the requestIdentity of a request is stored.
+type requestIdentityKey struct{}
+
+// requestIdentity keeps track of how the client of a request was identified, so that's only done once per request.
+type requestIdentity struct {
+	adminResolved bool
+	principal     string
+	role          string
+
+	tokenResolved bool
+	tokenTeam     *api.AdminTeam
+	tokenErr      error
+}
+
+// withRequestIdentity returns the request with a new (empty) requestIdentity attached.
+func withRequestIdentity(request *http.Request) *http.Request {
+	return request.WithContext(context.WithValue(request.Context(), requestIdentityKey{}, &requestIdentity{}))
+}
+
+// getRequestIdentity returns the requestIdentity of the request, or a throwaway one if none is attached.
+func getRequestIdentity(request *http.Request) *requestIdentity {
+	identity, ok := request.Context().Value(requestIdentityKey{}).(*requestIdentity)
+	if !ok {
+		return &requestIdentity{}
+	}
+
+	return identity
+}
+
+// getTokenTeam returns the team owning the token provided with the request.
+func (r *rest) getTokenTeam(request *http.Request) (*api.AdminTeam, error) {
+	identity := getRequestIdentity(request)
+	if !identity.tokenResolved {
+		identity.tokenTeam, identity.tokenErr = r.db.GetTeamForToken(request.Context(), getRequestToken(request))
+		identity.tokenResolved = true
+	}
+
+	if identity.tokenErr != nil {
+		return nil, identity.tokenErr
+	}
+
+	// Hand out a copy so the caller may modify it
+	team := *identity.tokenTeam
+	team.Tags = maps.Clone(team.Tags)
+
+	return &team, nil
+}
+
+// getLogURL returns the request URL with any token parameter masked, suitable for logs.
+func getLogURL(request *http.Request) string {
+	query := request.URL.Query()
+	if !query.Has("token") {
+		return request.URL.RequestURI()
+	}
+
+	query.Set("token", "*****")
+
+	u := *request.URL
+	u.RawQuery = query.Encode()
+
+	return u.RequestURI()
+}
+
+// getRequestTeam identifies the team making the request, by its certificate, its token or else by its IP.
+func (r *rest) getRequestTeam(request *http.Request) (*api.AdminTeam, error) {
+	cert := r.getClientCertificate(request)
+	if cert != nil && cert.Role == api.ClientCertificateRoleTeam {
+		return r.db.GetTeam(request.Context(), cert.TeamID)
+	}
+
+	if getRequestToken(request) != "" {
+		return r.getTokenTeam(request)
+	}
+
 	ip, err := r.getIP(request)
 	if err != nil {
-		return false
+		return nil, err
 	}
 
-	return slices.Contains(clusterPeers, ip.String())
+	return r.db.GetTeamForIP(request.Context(), *ip)
+}
+
+// requestTeam returns the team making the request or nil (after responding to the client) if there is none.
+func (r *rest) requestTeam(writer http.ResponseWriter, request *http.Request, logger log15.Logger) *api.AdminTeam {
+	team, err := r.getRequestTeam(request)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Warn("No team found for request", log15.Ctx{"client": request.RemoteAddr})
+		r.errorResponse(404, "No team found for IP or token", writer, request)
+
+		return nil
+	} else if err != nil {
+		logger.Error("Failed to get the team", log15.Ctx{"error": err})
+		r.errorResponse(500, "Internal Server Error", writer, request)
+
+		return nil
+	}
+
+	return team
+}
+
+// getClientCertificate returns the configured mapping for the verified client certificate of the request, if any.
+func (r *rest) getClientCertificate(request *http.Request) *api.ConfigClientCertificate {
+	if request.TLS == nil || len(request.TLS.VerifiedChains) == 0 {
+		return nil
+	}
+
+	cert := request.TLS.VerifiedChains[0][0]
+
+	names := []string{cert.Subject.CommonName}
+	names = append(names, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+
+	for _, uri := range cert.URIs {
+		names = append(names, uri.String())
+	}
+
+	for i, entry := range r.config.Daemon.ClientCertificates {
+		if slices.Contains(names, entry.Name) {
+			return &r.config.Daemon.ClientCertificates[i]
+		}
+	}
+
+	return nil
 }
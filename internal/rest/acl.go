@@ -0,0 +1,84 @@
+package rest
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/nsec/askgod/api"
+	"github.com/nsec/askgod/internal/subnets"
+)
+
+// acl is the compiled form of the subnets used for access control.
+type acl struct {
+	admins         *subnets.Index
+	teams          *subnets.Index
+	guests         *subnets.Index
+	trustedProxies *subnets.Index
+}
+
+// compileACL builds the access control lookup structure, rejecting any invalid subnet.
+func compileACL(config api.ConfigSubnets, trustedProxies []string) (*acl, error) {
+	var err error
+
+	resp := acl{}
+
+	resp.admins, err = compileSubnets("admin", config.Admins)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.teams, err = compileSubnets("team", config.Teams)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.guests, err = compileSubnets("guest", config.Guests)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.trustedProxies, err = compileSubnets("trusted proxy", trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// compileSubnets indexes a list of subnets, skipping empty entries.
+func compileSubnets(name string, entries []string) (*subnets.Index, error) {
+	idx := subnets.NewIndex()
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		_, subnet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s subnet %q: %w", name, entry, err)
+		}
+
+		err = idx.Add(subnet, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s subnet %q: %w", name, entry, err)
+		}
+	}
+
+	return idx, nil
+}
+
+// configACL refreshes the access control lookup structure from the current configuration.
+// The previous one is kept if the configuration is invalid.
+func (r *rest) configACL() error {
+	newACL, err := compileACL(r.config.Subnets, r.config.Daemon.TrustedProxies)
+	if err != nil {
+		return err
+	}
+
+	r.acl.Store(newACL)
+
+	return nil
+}
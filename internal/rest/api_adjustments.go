@@ -0,0 +1,265 @@
+package rest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/nsec/askgod/api"
+)
+
+func (r *rest) adminGetAdjustments(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	// Get all the adjustments from the database
+	adjustments, err := r.db.GetAdjustments(request.Context())
+	if err != nil {
+		logger.Error("Failed to query the adjustment list", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	r.jsonResponse(adjustments, writer, request)
+}
+
+func (r *rest) adminCreateAdjustment(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	// Decode the provided JSON input
+	newAdjustment := api.AdminAdjustmentPost{}
+
+	err := json.NewDecoder(request.Body).Decode(&newAdjustment)
+	if err != nil {
+		logger.Warn("Malformed JSON provided", log15.Ctx{"error": err})
+		r.errorResponse(400, "Malformed JSON provided", writer, request)
+
+		return
+	}
+
+	// Validate the input
+	err = r.validateAdjustment(request.Context(), newAdjustment.TeamID, newAdjustment.AdminAdjustmentPut)
+	if err != nil {
+		logger.Warn("Invalid adjustment provided", log15.Ctx{"error": err})
+		r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	// Attempt to update the database (attributed to the admin making the request)
+	author, _ := r.getAdminPrincipal(request)
+
+	id, err := r.db.CreateAdjustment(request.Context(), newAdjustment, author)
+	if err != nil {
+		logger.Error("Failed to create the adjustment", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	// Send the timeline notification
+	err = r.sendScoreUpdate(request.Context(), newAdjustment.TeamID, newAdjustment.Value, nil)
+	if err != nil {
+		logger.Error("Failed to send the score update", log15.Ctx{"error": err, "teamid": newAdjustment.TeamID})
+	}
+
+	logger.Info("New score adjustment defined", log15.Ctx{"id": id, "teamid": newAdjustment.TeamID, "value": newAdjustment.Value, "reason": newAdjustment.Reason, "author": author})
+}
+
+func (r *rest) adminGetAdjustment(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	idVar := request.PathValue("id")
+
+	// Convert the provided id to int
+	id, err := strconv.ParseInt(idVar, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid adjustment ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(400, "Invalid adjustment ID provided", writer, request)
+
+		return
+	}
+
+	// Attempt to get the DB record
+	adjustment, err := r.db.GetAdjustment(request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Warn("Invalid adjustment ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(404, "Invalid adjustment ID provided", writer, request)
+
+		return
+	} else if err != nil {
+		logger.Error("Failed to get the adjustment", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	r.jsonResponse(adjustment, writer, request)
+}
+
+func (r *rest) adminUpdateAdjustment(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	idVar := request.PathValue("id")
+
+	// Convert the provided id to int
+	id, err := strconv.ParseInt(idVar, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid adjustment ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(400, "Invalid adjustment ID provided", writer, request)
+
+		return
+	}
+
+	// Decode the provided JSON input
+	newAdjustment := api.AdminAdjustmentPut{}
+
+	err = json.NewDecoder(request.Body).Decode(&newAdjustment)
+	if err != nil {
+		logger.Warn("Malformed JSON provided", log15.Ctx{"error": err})
+		r.errorResponse(400, "Malformed JSON provided", writer, request)
+
+		return
+	}
+
+	// Get the current entry
+	currentAdjustment, err := r.db.GetAdjustment(request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Warn("Invalid adjustment ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(404, "Invalid adjustment ID provided", writer, request)
+
+		return
+	} else if err != nil {
+		logger.Error("Failed to get the adjustment", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	// Validate the input
+	err = r.validateAdjustment(request.Context(), currentAdjustment.TeamID, newAdjustment)
+	if err != nil {
+		logger.Warn("Invalid adjustment provided", log15.Ctx{"error": err})
+		r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	// Attempt to update the database
+	err = r.db.UpdateAdjustment(request.Context(), id, newAdjustment)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Warn("Invalid adjustment ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(404, "Invalid adjustment ID provided", writer, request)
+
+		return
+	} else if err != nil {
+		logger.Error("Failed to update the adjustment", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	// Send the timeline notification
+	if newAdjustment.Value != currentAdjustment.Value {
+		err = r.sendScoreUpdate(request.Context(), currentAdjustment.TeamID, newAdjustment.Value-currentAdjustment.Value, nil)
+		if err != nil {
+			logger.Error("Failed to send the score update", log15.Ctx{"error": err, "teamid": currentAdjustment.TeamID})
+		}
+	}
+
+	logger.Info("Score adjustment updated", log15.Ctx{"id": id, "value": newAdjustment.Value, "reason": newAdjustment.Reason})
+}
+
+func (r *rest) adminDeleteAdjustment(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	idVar := request.PathValue("id")
+
+	// Convert the provided id to int
+	id, err := strconv.ParseInt(idVar, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid adjustment ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(400, "Invalid adjustment ID provided", writer, request)
+
+		return
+	}
+
+	// Get the current entry
+	currentAdjustment, err := r.db.GetAdjustment(request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Warn("Invalid adjustment ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(404, "Invalid adjustment ID provided", writer, request)
+
+		return
+	} else if err != nil {
+		logger.Error("Failed to get the adjustment", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	// Attempt to delete the DB record
+	err = r.db.DeleteAdjustment(request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Warn("Invalid adjustment ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(404, "Invalid adjustment ID provided", writer, request)
+
+		return
+	} else if err != nil {
+		logger.Error("Failed to delete the adjustment", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	// Send the timeline notification
+	err = r.sendScoreUpdate(request.Context(), currentAdjustment.TeamID, -currentAdjustment.Value, nil)
+	if err != nil {
+		logger.Error("Failed to send the score update", log15.Ctx{"error": err, "teamid": currentAdjustment.TeamID})
+	}
+
+	logger.Info("Score adjustment deleted", log15.Ctx{"id": id})
+}
+
+func (r *rest) adminClearAdjustments(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	request.Body = http.MaxBytesReader(writer, request.Body, 1024*1024)
+
+	emptyVar := request.FormValue("empty")
+
+	// Confirm the user is sure about it
+	if emptyVar != "1" {
+		logger.Warn("Adjustments clear requested without empty=1")
+		r.errorResponse(400, "Adjustments clear requested without empty=1", writer, request)
+
+		return
+	}
+
+	// Clear the database entries
+	err := r.db.ClearAdjustments(request.Context())
+	if err != nil {
+		logger.Error("Failed to clear all adjustments", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	logger.Info("All score adjustments deleted")
+
+	// Tell everyone to reload
+	_ = r.eventSend(request.Context(), "timeline", api.EventTimeline{Type: "reload"})
+}
+
+func (r *rest) validateAdjustment(ctx context.Context, teamid int64, adjustment api.AdminAdjustmentPut) error {
+	if adjustment.Value == 0 {
+		return errors.New("adjustment value can't be zero")
+	}
+
+	if adjustment.Reason == "" {
+		return errors.New("adjustment reason is required")
+	}
+
+	_, err := r.db.GetTeam(ctx, teamid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("adjustment references unknown team %d", teamid)
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/nsec/askgod/internal/database"
+)
+
+func (r *rest) adminGetAudit(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	filter := database.AuditFilter{
+		Principal: request.FormValue("principal"),
+		Target:    request.FormValue("target"),
+	}
+
+	// Parse the limit
+	limit := request.FormValue("limit")
+	if limit != "" {
+		parsed, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || parsed < 0 {
+			logger.Warn("Invalid audit filter", log15.Ctx{"key": "limit", "value": limit})
+			r.errorResponse(400, "Invalid filter", writer, request)
+
+			return
+		}
+
+		filter.Limit = parsed
+	}
+
+	// Parse the time filters
+	for key, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		value := request.FormValue(key)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			logger.Warn("Invalid audit filter", log15.Ctx{"key": key, "value": value})
+			r.errorResponse(400, "Invalid filter", writer, request)
+
+			return
+		}
+
+		*target = parsed
+	}
+
+	// Get the entries from the database
+	entries, err := r.db.GetAuditEntries(request.Context(), filter)
+	if err != nil {
+		logger.Error("Failed to query the audit log", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	r.jsonResponse(entries, writer, request)
+}
@@ -22,6 +22,10 @@ func (r *rest) getConfig(writer http.ResponseWriter, request *http.Request, _ lo
 		resp.Daemon.HTTPSKey = "*****"
 	}
 
+	if resp.Daemon.ClusterKey != "" {
+		resp.Daemon.ClusterKey = "*****"
+	}
+
 	if resp.Database.Password != "" {
 		resp.Database.Password = "*****"
 	}
@@ -41,6 +45,44 @@ func (r *rest) updateConfig(writer http.ResponseWriter, request *http.Request, l
 		return
 	}
 
+	// Validate the input
+	if !slices.Contains([]string{"", api.ScoringModeStatic, api.ScoringModeDynamic}, req.Scoring.Mode) {
+		logger.Warn("Invalid scoring mode", log15.Ctx{"mode": req.Scoring.Mode})
+		r.errorResponse(400, "Invalid scoring mode", writer, request)
+
+		return
+	}
+
+	if !slices.Contains([]string{"", api.TiebreakLastSubmit, api.TiebreakFirstReach, api.TiebreakFirstBloods, api.TiebreakShared}, req.Scoring.Tiebreak) {
+		logger.Warn("Invalid tiebreak policy", log15.Ctx{"tiebreak": req.Scoring.Tiebreak})
+		r.errorResponse(400, "Invalid tiebreak policy", writer, request)
+
+		return
+	}
+
+	err = validateRateLimit(req.Scoring)
+	if err != nil {
+		logger.Warn("Invalid rate limit configuration", log15.Ctx{"error": err})
+		r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	if !req.Scoring.StartTime.IsZero() && !req.Scoring.EndTime.IsZero() && !req.Scoring.EndTime.After(req.Scoring.StartTime) {
+		logger.Warn("Invalid event schedule", log15.Ctx{"start": req.Scoring.StartTime, "end": req.Scoring.EndTime})
+		r.errorResponse(400, "The event must end after it starts", writer, request)
+
+		return
+	}
+
+	newACL, err := compileACL(req.Subnets, r.config.Daemon.TrustedProxies)
+	if err != nil {
+		logger.Warn("Invalid subnet configuration", log15.Ctx{"error": err})
+		r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
 	// Save old config
 	oldConfig := r.config.ConfigPut
 	newConfig := req
@@ -54,8 +96,9 @@ func (r *rest) updateConfig(writer http.ResponseWriter, request *http.Request, l
 		return
 	}
 
-	_ = r.eventSend("internal", api.EventInternal{Type: "config-updated"})
+	_ = r.eventSend(request.Context(), "internal", api.EventInternal{Type: "config-updated"})
 	r.config.ConfigPut = newConfig
+	r.acl.Store(newACL)
 
 	err = r.configHiddenTeams(request.Context())
 	if err != nil {
@@ -65,10 +108,15 @@ func (r *rest) updateConfig(writer http.ResponseWriter, request *http.Request, l
 		return
 	}
 
+	// Replay the events held back if the freeze was lifted
+	if scoringFrozen(oldConfig.Scoring) && !r.isFrozen() {
+		r.eventReplayFrozen(request.Context(), oldConfig.Scoring.FreezeAt)
+	}
+
 	logger.Info("Config updated", log15.Ctx{"old": oldConfig, "new": newConfig})
 
 	// Tell everyone to reload
-	_ = r.eventSend("timeline", api.EventTimeline{Type: "reload"})
+	_ = r.eventSend(request.Context(), "timeline", api.EventTimeline{Type: "reload"})
 }
 
 func (r *rest) configHiddenTeams(ctx context.Context) error {
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
@@ -31,11 +32,12 @@ type eventListener struct {
 	connection   *websocket.Conn
 	messageTypes []string
 
-	active  chan bool
-	id      string
-	msgLock sync.Mutex
-	peer    bool
-	teamid  int64
+	active   chan bool
+	division string
+	id       string
+	msgLock  sync.Mutex
+	peer     bool
+	teamid   int64
 }
 
 // upgrader is a websocket upgrader which ignores the request Origin.
@@ -83,6 +85,15 @@ func (r *rest) injectEvents(writer http.ResponseWriter, request *http.Request, l
 
 		err = json.Unmarshal(data, &apiEvent)
 		if err == nil && apiEvent.Type == "internal" {
+			internalEvent := api.EventInternal{}
+
+			err = json.Unmarshal(apiEvent.Metadata, &internalEvent)
+			if err == nil && internalEvent.Type == "teams-updated" {
+				r.db.InvalidateTeamCache()
+
+				continue
+			}
+
 			conf, err := r.db.GetConfig(request.Context())
 			if err != nil {
 				logger.Error("Failed to get new configuration", log15.Ctx{"error": err})
@@ -97,6 +108,12 @@ func (r *rest) injectEvents(writer http.ResponseWriter, request *http.Request, l
 			r.config.ConfigPut = *newConfig
 			logger.Info("Config updated", log15.Ctx{"old": oldConfig, "new": newConfig})
 
+			// Keep the previous subnet ACL if the new one is invalid
+			err = r.configACL()
+			if err != nil {
+				logger.Error("Invalid subnet configuration from peer, keeping the previous one", log15.Ctx{"error": err})
+			}
+
 			err = r.configHiddenTeams(request.Context())
 			if err != nil {
 				logger.Error("Failed to update hidden teams", log15.Ctx{"error": err})
@@ -104,10 +121,15 @@ func (r *rest) injectEvents(writer http.ResponseWriter, request *http.Request, l
 				continue
 			}
 
+			// Replay the events held back if the freeze was lifted
+			if scoringFrozen(oldConfig.Scoring) && !r.isFrozen() {
+				r.eventReplayFrozen(request.Context(), oldConfig.Scoring.FreezeAt)
+			}
+
 			continue
 		}
 
-		err = r.eventSendRaw(rawEvent)
+		err = r.eventSendRaw(rawEvent, false)
 		if err != nil {
 			logger.Error("Failed to relay event from peer", log15.Ctx{"error": err})
 
@@ -140,7 +162,7 @@ func (r *rest) getEvents(writer http.ResponseWriter, request *http.Request, logg
 	eventTypes := strings.Split(typeStr, ",")
 	for _, entry := range eventTypes {
 		// Make sure that all types are valid
-		if !slices.Contains([]string{"timeline", "logging", "flags"}, entry) {
+		if !slices.Contains([]string{"timeline", "first-blood", "logging", "flags"}, entry) {
 			logger.Warn("Invalid event type", log15.Ctx{"type": entry})
 			r.errorResponse(400, "Invalid event type", writer, request)
 
@@ -165,22 +187,13 @@ func (r *rest) getEvents(writer http.ResponseWriter, request *http.Request, logg
 		return
 	}
 
-	// Extract the client IP
-	ip, err := r.getIP(request)
-	if err != nil {
-		logger.Error("Failed to get the client's IP", log15.Ctx{"error": err})
-		r.errorResponse(500, "Internal Server Error", writer, request)
-
-		return
-	}
-
 	// Get the team id
 	teamid := int64(0)
 
 	if r.hasAccess("admin", request) {
 		teamid = -1
 	} else {
-		team, err := r.db.GetTeamForIP(request.Context(), *ip)
+		team, err := r.getRequestTeam(request)
 		if err == nil {
 			teamid = team.ID
 		}
@@ -189,6 +202,7 @@ func (r *rest) getEvents(writer http.ResponseWriter, request *http.Request, logg
 	// Prepare the listener
 	listener.active = make(chan bool, 1)
 	listener.connection = c
+	listener.division = request.FormValue("division")
 	listener.id = uuid.New().String()
 	listener.messageTypes = eventTypes
 	listener.teamid = teamid
@@ -210,12 +224,19 @@ func (r *rest) getEvents(writer http.ResponseWriter, request *http.Request, logg
 	r.logger.Debug("Disconnected events listener", log15.Ctx{"uuid": listener.id})
 }
 
-func (r *rest) eventSend(eventType string, eventMessage any) error {
-	event := map[string]any{}
-	event["type"] = eventType
-	event["timestamp"] = time.Now()
-	event["metadata"] = eventMessage
+func (r *rest) eventSend(ctx context.Context, eventType string, eventMessage any) error {
+	return r.eventSendNew(ctx, eventType, eventMessage, false)
+}
 
+// eventSendLocal sends an event to the clients of this server only, without forwarding it to cluster peers.
+// This is used for events every cluster member generates on its own.
+func (r *rest) eventSendLocal(ctx context.Context, eventType string, eventMessage any) error {
+	return r.eventSendNew(ctx, eventType, eventMessage, true)
+}
+
+// eventSendNew sends a new event originating from this server.
+// Events sent while handling an admin request are attributed to the admin's principal.
+func (r *rest) eventSendNew(ctx context.Context, eventType string, eventMessage any, local bool) error {
 	if eventHostname == "" {
 		hostname, err := os.Hostname()
 		if err != nil {
@@ -225,12 +246,28 @@ func (r *rest) eventSend(eventType string, eventMessage any) error {
 		eventHostname = hostname
 	}
 
+	event := newEvent(eventType, eventMessage)
+
+	identity, ok := ctx.Value(requestIdentityKey{}).(*requestIdentity)
+	if ok && identity.principal != "" {
+		event["principal"] = identity.principal
+	}
+
+	return r.eventSendRaw(event, local)
+}
+
+// newEvent wraps the message into an event originating from this server.
+func newEvent(eventType string, eventMessage any) map[string]any {
+	event := map[string]any{}
+	event["type"] = eventType
+	event["timestamp"] = time.Now()
+	event["metadata"] = eventMessage
 	event["server"] = eventHostname
 
-	return r.eventSendRaw(event)
+	return event
 }
 
-func (r *rest) eventSendRaw(raw any) error {
+func (r *rest) eventSendRaw(raw any, local bool) error {
 	body, err := json.Marshal(raw)
 	if err != nil {
 		return err
@@ -243,12 +280,43 @@ func (r *rest) eventSendRaw(raw any) error {
 		return err
 	}
 
+	// Score changes of other teams are held back while the scoreboard is frozen
+	held := false
+	heldTeamID := int64(0)
+
+	if (event.Type == "timeline" || event.Type == "first-blood") && r.isFrozen() {
+		timeline := api.EventTimeline{}
+
+		err = json.Unmarshal(event.Metadata, &timeline)
+		if err != nil {
+			return err
+		}
+
+		if timeline.TeamID > 0 && (timeline.Score != nil || event.Type == "first-blood") {
+			held = true
+			heldTeamID = timeline.TeamID
+		}
+	}
+
+	// Only admins and peers get to know which admin caused the event
+	publicBody := body
+
+	if event.Principal != "" {
+		publicEvent := event
+		publicEvent.Principal = ""
+
+		publicBody, err = json.Marshal(publicEvent)
+		if err != nil {
+			return err
+		}
+	}
+
 	eventsLock.Lock()
 
 	listeners := eventListeners
 	for _, listener := range listeners {
-		// Don't re-transmit cluster events
-		if event.Server != eventHostname && listener.peer {
+		// Don't re-transmit cluster events (or send local ones to peers)
+		if listener.peer && (local || event.Server != eventHostname) {
 			continue
 		}
 
@@ -258,7 +326,8 @@ func (r *rest) eventSendRaw(raw any) error {
 		}
 
 		// If a team message and hide_others is in effect, restrict broadcast
-		if event.Type == "timeline" {
+		// (first-blood events use the same teamid field as timeline events)
+		if event.Type == "timeline" || event.Type == "first-blood" {
 			timeline := api.EventTimeline{}
 
 			err = json.Unmarshal(event.Metadata, &timeline)
@@ -266,17 +335,26 @@ func (r *rest) eventSendRaw(raw any) error {
 				return err
 			}
 
-			if timeline.TeamID > 0 && listener.teamid != -1 && timeline.TeamID != listener.teamid {
-				if r.config.Scoring.HideOthers {
-					continue
-				}
+			if !r.eventVisible(listener, timeline.TeamID) {
+				continue
+			}
 
-				if slices.Contains(r.hiddenTeams, timeline.TeamID) {
-					continue
-				}
+			// Restrict to the requested division (removals are always sent)
+			if listener.division != "" && timeline.TeamID > 0 && timeline.Type != "team-removed" && timeline.Division != listener.division {
+				continue
 			}
 		}
 
+		// Only admins, peers and the team itself get held back events
+		if held && !listener.peer && listener.teamid != -1 && listener.teamid != heldTeamID {
+			continue
+		}
+
+		msg := body
+		if !listener.peer && listener.teamid != -1 {
+			msg = publicBody
+		}
+
 		go func(listener *eventListener, body []byte) {
 			if listener == nil {
 				return
@@ -289,13 +367,28 @@ func (r *rest) eventSendRaw(raw any) error {
 			if err != nil {
 				listener.active <- false
 			}
-		}(listener, body)
+		}(listener, msg)
 	}
 	eventsLock.Unlock()
 
 	return nil
 }
 
+// eventVisible checks whether an event related to the given team may be sent to the listener.
+func (r *rest) eventVisible(listener *eventListener, teamid int64) bool {
+	if teamid > 0 && listener.teamid != -1 && teamid != listener.teamid {
+		if r.config.Scoring.HideOthers {
+			return false
+		}
+
+		if slices.Contains(r.hiddenTeams, teamid) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func logContextMap(ctx []any) map[string]string {
 	var key string
 
@@ -382,6 +475,18 @@ func (r *rest) forwardEvents(peer string) {
 		tlsConfig.RootCAs = caCertPool
 	}
 
+	// Identify ourselves to peers verifying client certificates
+	if r.config.Daemon.ClusterCertificate != "" {
+		keypair, err := utils.ReadKeyPair(r.config.Daemon.ClusterCertificate, r.config.Daemon.ClusterKey)
+		if err != nil {
+			r.logger.Error("Failed to load cluster keypair", log15.Ctx{"error": err, "peer": peer})
+
+			return
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{keypair}
+	}
+
 	dialer := websocket.Dialer{
 		TLSClientConfig: tlsConfig,
 	}
@@ -425,7 +530,7 @@ type EventsLogHandler struct{}
 // Log send a log message through websocket.
 func (EventsLogHandler) Log(rec *log15.Record) error {
 	r := rest{}
-	_ = r.eventSend("logging", api.EventLogging{
+	_ = r.eventSend(context.Background(), "logging", api.EventLogging{
 		Message: rec.Msg,
 		Level:   rec.Lvl.String(),
 		Context: logContextMap(rec.Ctx),
@@ -0,0 +1,186 @@
+package rest
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/nsec/askgod/api"
+	"github.com/nsec/askgod/internal/database"
+)
+
+func (r *rest) adminGetFlagVariants(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	idVar := request.PathValue("id")
+
+	// Convert the provided id to int
+	id, err := strconv.ParseInt(idVar, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid flag ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(400, "Invalid flag ID provided", writer, request)
+
+		return
+	}
+
+	// Get all the variants from the database
+	variants, err := r.db.GetFlagVariants(request.Context(), id)
+	if err != nil {
+		logger.Error("Failed to query the flag variant list", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	r.jsonResponse(variants, writer, request)
+}
+
+func (r *rest) adminCreateFlagVariants(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	idVar := request.PathValue("id")
+
+	// Convert the provided id to int
+	id, err := strconv.ParseInt(idVar, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid flag ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(400, "Invalid flag ID provided", writer, request)
+
+		return
+	}
+
+	// Get the flag
+	flag, err := r.db.GetFlag(request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Warn("Invalid flag ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(404, "Invalid flag ID provided", writer, request)
+
+		return
+	} else if err != nil {
+		logger.Error("Failed to get the flag", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	request.Body = http.MaxBytesReader(writer, request.Body, 1024*1024)
+
+	newVariants := []api.AdminFlagVariantPost{}
+
+	generateVar := request.FormValue("generate")
+	if generateVar == "1" {
+		// Generate a variant for every team which doesn't have one yet
+		newVariants, err = r.generateFlagVariants(request, flag)
+		if err != nil {
+			logger.Error("Failed to generate the flag variants", log15.Ctx{"error": err})
+			r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+			return
+		}
+	} else {
+		// Decode the provided JSON input
+		err = json.NewDecoder(request.Body).Decode(&newVariants)
+		if err != nil {
+			logger.Warn("Malformed JSON provided", log15.Ctx{"error": err})
+			r.errorResponse(400, "Malformed JSON provided", writer, request)
+
+			return
+		}
+	}
+
+	for _, variant := range newVariants {
+		// Attempt to create the database record
+		variantID, err := r.db.CreateFlagVariant(request.Context(), flag.ID, variant)
+		if errors.Is(err, database.ErrFlagVariantConflict) {
+			logger.Warn("Flag variant conflicts with an existing flag", log15.Ctx{"flagid": flag.ID, "teamid": variant.TeamID})
+			r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
+			return
+		} else if err != nil {
+			logger.Error("Failed to create the flag variant", log15.Ctx{"error": err})
+			r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+			return
+		}
+
+		logger.Info("New flag variant defined", log15.Ctx{"id": variantID, "flagid": flag.ID, "teamid": variant.TeamID})
+	}
+}
+
+func (r *rest) generateFlagVariants(request *http.Request, flag *api.AdminFlag) ([]api.AdminFlagVariantPost, error) {
+	teams, err := r.db.GetTeams(request.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := r.db.GetFlagVariants(request.Context(), flag.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := map[int64]bool{}
+	for _, variant := range variants {
+		existing[variant.TeamID] = true
+	}
+
+	resp := []api.AdminFlagVariantPost{}
+
+	for _, team := range teams {
+		if existing[team.ID] {
+			continue
+		}
+
+		suffix := make([]byte, 8)
+
+		_, err := rand.Read(suffix)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = append(resp, api.AdminFlagVariantPost{
+			TeamID: team.ID,
+			Flag:   fmt.Sprintf("%s-%s", flag.Flag, hex.EncodeToString(suffix)),
+		})
+	}
+
+	return resp, nil
+}
+
+func (r *rest) adminClearFlagVariants(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	idVar := request.PathValue("id")
+
+	// Convert the provided id to int
+	id, err := strconv.ParseInt(idVar, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid flag ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(400, "Invalid flag ID provided", writer, request)
+
+		return
+	}
+
+	request.Body = http.MaxBytesReader(writer, request.Body, 1024*1024)
+
+	emptyVar := request.FormValue("empty")
+
+	// Confirm the user is sure about it
+	if emptyVar != "1" {
+		logger.Warn("Flag variants clear requested without empty=1")
+		r.errorResponse(400, "Flag variants clear requested without empty=1", writer, request)
+
+		return
+	}
+
+	// Clear the database entries
+	err = r.db.ClearFlagVariants(request.Context(), id)
+	if err != nil {
+		logger.Error("Failed to clear the flag variants", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	logger.Info("All flag variants deleted", log15.Ctx{"flagid": id})
+}
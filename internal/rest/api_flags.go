@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -14,29 +15,13 @@ import (
 	"github.com/inconshreveable/log15"
 
 	"github.com/nsec/askgod/api"
+	"github.com/nsec/askgod/internal/database"
 )
 
 func (r *rest) getTeamFlags(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
-	// Extract the client IP
-	ip, err := r.getIP(request)
-	if err != nil {
-		logger.Error("Failed to get the client's IP", log15.Ctx{"error": err})
-		r.errorResponse(500, "Internal Server Error", writer, request)
-
-		return
-	}
-
 	// Look for a matching team
-	team, err := r.db.GetTeamForIP(request.Context(), *ip)
-	if errors.Is(err, sql.ErrNoRows) {
-		logger.Warn("No team found for IP", log15.Ctx{"ip": ip.String()})
-		r.errorResponse(404, "No team found for IP", writer, request)
-
-		return
-	} else if err != nil {
-		logger.Error("Failed to get the team", log15.Ctx{"error": err})
-		r.errorResponse(500, "Internal Server Error", writer, request)
-
+	team := r.requestTeam(writer, request, logger)
+	if team == nil {
 		return
 	}
 
@@ -64,26 +49,9 @@ func (r *rest) getTeamFlag(writer http.ResponseWriter, request *http.Request, lo
 		return
 	}
 
-	// Extract the client IP
-	ip, err := r.getIP(request)
-	if err != nil {
-		logger.Error("Failed to get the client's IP", log15.Ctx{"error": err})
-		r.errorResponse(500, "Internal Server Error", writer, request)
-
-		return
-	}
-
 	// Look for a matching team
-	team, err := r.db.GetTeamForIP(request.Context(), *ip)
-	if errors.Is(err, sql.ErrNoRows) {
-		logger.Warn("No team found for IP", log15.Ctx{"ip": ip.String()})
-		r.errorResponse(404, "No team found for IP", writer, request)
-
-		return
-	} else if err != nil {
-		logger.Error("Failed to get the team", log15.Ctx{"error": err})
-		r.errorResponse(500, "Internal Server Error", writer, request)
-
+	team := r.requestTeam(writer, request, logger)
+	if team == nil {
 		return
 	}
 
@@ -130,26 +98,9 @@ func (r *rest) updateTeamFlag(writer http.ResponseWriter, request *http.Request,
 		return
 	}
 
-	// Extract the client IP
-	ip, err := r.getIP(request)
-	if err != nil {
-		logger.Error("Failed to get the client's IP", log15.Ctx{"error": err})
-		r.errorResponse(500, "Internal Server Error", writer, request)
-
-		return
-	}
-
 	// Look for a matching team
-	team, err := r.db.GetTeamForIP(request.Context(), *ip)
-	if errors.Is(err, sql.ErrNoRows) {
-		logger.Warn("No team found for IP", log15.Ctx{"ip": ip.String()})
-		r.errorResponse(404, "No team found for IP", writer, request)
-
-		return
-	} else if err != nil {
-		logger.Error("Failed to get the team", log15.Ctx{"error": err})
-		r.errorResponse(500, "Internal Server Error", writer, request)
-
+	team := r.requestTeam(writer, request, logger)
+	if team == nil {
 		return
 	}
 
@@ -171,6 +122,19 @@ func (r *rest) submitTeamFlag(writer http.ResponseWriter, request *http.Request,
 		return
 	}
 
+	// Check the event schedule
+	switch state, _ := r.eventState(); state {
+	case api.EventStatePending:
+		r.errorResponse(403, "The event hasn't started yet", writer, request)
+
+		return
+	case api.EventStateEnded:
+		r.errorResponse(403, "The event is over", writer, request)
+
+		return
+	default:
+	}
+
 	// Limit the request body size to avoid abuse.
 	request.Body = http.MaxBytesReader(writer, request.Body, 1024*1024)
 
@@ -203,6 +167,12 @@ func (r *rest) submitTeamFlag(writer http.ResponseWriter, request *http.Request,
 
 	flag.Source = source
 
+	// Look for a matching team
+	team := r.requestTeam(writer, request, logger)
+	if team == nil {
+		return
+	}
+
 	// Extract the client IP
 	ip, err := r.getIP(request)
 	if err != nil {
@@ -212,22 +182,17 @@ func (r *rest) submitTeamFlag(writer http.ResponseWriter, request *http.Request,
 		return
 	}
 
-	// Look for a matching team
-	team, err := r.db.GetTeamForIP(request.Context(), *ip)
-	if errors.Is(err, sql.ErrNoRows) {
-		logger.Warn("No team found for IP", log15.Ctx{"ip": ip.String()})
-		r.errorResponse(404, "No team found for IP", writer, request)
+	// Keep a record of the attempt
+	outcome := "error"
+	flagID := int64(0)
 
-		return
-	} else if err != nil {
-		logger.Error("Failed to get the team", log15.Ctx{"error": err})
-		r.errorResponse(500, "Internal Server Error", writer, request)
-
-		return
-	}
+	defer func() {
+		r.recordSubmission(request.Context(), logger, team.ID, *ip, flag, flagID, outcome)
+	}()
 
 	// Check that the team is configured
 	if team.Name == "" || team.Country == "" {
+		outcome = "unconfigured"
 		metricSubmitTeam.WithLabelValues(strconv.FormatInt(team.ID, 10), "unconfigured").Inc()
 		logger.Debug("Unconfigured team tried to submit flag", log15.Ctx{"teamid": team.ID})
 		r.errorResponse(400, "Team name and country are required to participate", writer, request)
@@ -235,20 +200,100 @@ func (r *rest) submitTeamFlag(writer http.ResponseWriter, request *http.Request,
 		return
 	}
 
+	// Check the rate limits
+	if r.checkRateLimit(writer, request, logger, team.ID, *ip) {
+		outcome = "ratelimited"
+
+		return
+	}
+
 	// Submit the flag
-	result, adminFlag, err := r.db.SubmitTeamFlag(request.Context(), team.ID, flag)
+	var errShared *database.FlagSharedError
+
+	result, adminFlag, err := r.db.SubmitTeamFlag(request.Context(), team.ID, flag, r.config.Scoring)
+	if adminFlag != nil {
+		flagID = adminFlag.ID
+	}
+
 	switch {
+	case errors.As(err, &errShared):
+		outcome = "shared"
+		metricSubmitTeam.WithLabelValues(strconv.FormatInt(team.ID, 10), outcome).Inc()
+
+		owner, errOwner := r.db.GetTeam(request.Context(), errShared.TeamID)
+		if errOwner != nil {
+			logger.Error("Failed to get the flag variant owner", log15.Ctx{"error": errOwner, "teamid": errShared.TeamID})
+		}
+
+		_ = r.eventSend(request.Context(), "flags", api.EventFlag{Team: *team, Flag: adminFlag, Input: flag.Flag, Value: 0, Type: "shared", Source: flag.Source, Owner: owner})
+		logger.Warn("Flag variant of another team submitted", log15.Ctx{"teamid": team.ID, "ownerid": errShared.TeamID, "source": flag.Source, "flag": flag.Flag})
+		r.recordInvalidSubmission(request.Context(), logger, team.ID, *ip)
+		r.errorResponse(400, "Invalid flag submitted", writer, request)
+
+		return
+
 	case errors.Is(err, sql.ErrNoRows):
-		metricSubmitTeam.WithLabelValues(strconv.FormatInt(team.ID, 10), "invalid").Inc()
-		_ = r.eventSend("flags", api.EventFlag{Team: *team, Input: flag.Flag, Type: "invalid", Source: flag.Source})
-		logger.Info("Invalid flag submitted", log15.Ctx{"teamid": team.ID, "source": flag.Source, "flag": flag.Flag})
+		// Check if the input is close to an existing flag
+		nearMissID, nearMiss, errNearMiss := r.db.FindNearMiss(request.Context(), team.ID, flag.Flag)
+		if errNearMiss == nil {
+			outcome = api.SubmissionNearMiss
+			flagID = nearMissID
+			metricSubmitTeam.WithLabelValues(strconv.FormatInt(team.ID, 10), outcome).Inc()
+
+			nearMissFlag, errFlag := r.db.GetFlag(request.Context(), nearMissID)
+			if errFlag != nil {
+				logger.Error("Failed to get the closest flag", log15.Ctx{"error": errFlag, "flagid": nearMissID})
+			}
+
+			_ = r.eventSend(request.Context(), "flags", api.EventFlag{Team: *team, Flag: nearMissFlag, Input: flag.Flag, Type: outcome, Source: flag.Source, NearMiss: nearMiss})
+			logger.Info("Near-miss flag submitted", log15.Ctx{"teamid": team.ID, "flagid": nearMissID, "reason": nearMiss, "source": flag.Source, "flag": flag.Flag})
+		} else {
+			if !errors.Is(errNearMiss, sql.ErrNoRows) {
+				logger.Error("Failed to look for a near miss", log15.Ctx{"error": errNearMiss, "teamid": team.ID})
+			}
+
+			outcome = "invalid"
+			metricSubmitTeam.WithLabelValues(strconv.FormatInt(team.ID, 10), outcome).Inc()
+			_ = r.eventSend(request.Context(), "flags", api.EventFlag{Team: *team, Input: flag.Flag, Type: "invalid", Source: flag.Source})
+			logger.Info("Invalid flag submitted", log15.Ctx{"teamid": team.ID, "source": flag.Source, "flag": flag.Flag})
+		}
+
+		r.recordInvalidSubmission(request.Context(), logger, team.ID, *ip)
 		r.errorResponse(400, "Invalid flag submitted", writer, request)
 
 		return
 
+	case errors.Is(err, database.ErrFlagLocked):
+		outcome = "locked"
+		metricSubmitTeam.WithLabelValues(strconv.FormatInt(team.ID, 10), outcome).Inc()
+		_ = r.eventSend(request.Context(), "flags", api.EventFlag{Team: *team, Flag: adminFlag, Input: flag.Flag, Value: 0, Type: "locked", Source: flag.Source})
+		logger.Info("Locked flag submitted", log15.Ctx{"teamid": team.ID, "source": flag.Source, "flag": flag.Flag})
+		r.errorResponse(400, "This flag is locked until its prerequisites are solved", writer, request)
+
+		return
+
+	case errors.Is(err, database.ErrFlagNotActive):
+		outcome = "not-active"
+		metricSubmitTeam.WithLabelValues(strconv.FormatInt(team.ID, 10), outcome).Inc()
+		_ = r.eventSend(request.Context(), "flags", api.EventFlag{Team: *team, Flag: adminFlag, Input: flag.Flag, Value: 0, Type: "not-active", Source: flag.Source})
+		logger.Info("Not yet active flag submitted", log15.Ctx{"teamid": team.ID, "source": flag.Source, "flag": flag.Flag})
+		r.errorResponse(400, "This flag can't be submitted yet", writer, request)
+
+		return
+
+	case errors.Is(err, database.ErrFlagExpired):
+		outcome = "expired"
+		metricSubmitTeam.WithLabelValues(strconv.FormatInt(team.ID, 10), outcome).Inc()
+		_ = r.eventSend(request.Context(), "flags", api.EventFlag{Team: *team, Flag: adminFlag, Input: flag.Flag, Value: 0, Type: "expired", Source: flag.Source})
+		logger.Info("Expired flag submitted", log15.Ctx{"teamid": team.ID, "source": flag.Source, "flag": flag.Flag})
+		r.errorResponse(400, "This flag has expired", writer, request)
+
+		return
+
 	case errors.Is(err, os.ErrExist):
-		metricSubmitTeam.WithLabelValues(strconv.FormatInt(team.ID, 10), "duplicate").Inc()
-		_ = r.eventSend("flags", api.EventFlag{Team: *team, Flag: adminFlag, Input: flag.Flag, Value: 0, Type: "duplicate", Source: flag.Source})
+		outcome = "duplicate"
+		metricSubmitTeam.WithLabelValues(strconv.FormatInt(team.ID, 10), outcome).Inc()
+		_ = r.eventSend(request.Context(), "flags", api.EventFlag{Team: *team, Flag: adminFlag, Input: flag.Flag, Value: 0, Type: "duplicate", Source: flag.Source})
 		logger.Info("The flag was already submitted", log15.Ctx{"teamid": team.ID, "source": flag.Source, "flag": flag.Flag})
 		r.errorResponse(400, "The flag was already submitted", writer, request)
 
@@ -262,10 +307,28 @@ func (r *rest) submitTeamFlag(writer http.ResponseWriter, request *http.Request,
 		return
 	}
 
-	metricSubmitTeam.WithLabelValues(strconv.FormatInt(team.ID, 10), "valid").Inc()
+	submitType := "valid"
+	if adminFlag.Trap {
+		submitType = "trap"
+	}
+
+	outcome = submitType
+	metricSubmitTeam.WithLabelValues(strconv.FormatInt(team.ID, 10), submitType).Inc()
 
 	// Send the flag notification
-	_ = r.eventSend("flags", api.EventFlag{Team: *team, Flag: adminFlag, Input: flag.Flag, Value: result.Value, Type: "valid", Source: flag.Source})
+	_ = r.eventSend(request.Context(), "flags", api.EventFlag{Team: *team, Flag: adminFlag, Input: flag.Flag, Value: result.Value, Type: submitType, Source: flag.Source})
+
+	// Flag the team for review
+	if adminFlag.Trap {
+		err := r.db.SetTeamTag(request.Context(), team.ID, "review", "trap")
+		if err != nil {
+			logger.Error("Failed to flag the team for review", log15.Ctx{"error": err, "teamid": team.ID})
+		} else {
+			r.teamsUpdated(request.Context())
+		}
+
+		logger.Warn("Trap flag submitted", log15.Ctx{"teamid": team.ID, "flagid": adminFlag.ID, "value": result.Value, "source": flag.Source, "flag": flag.Flag})
+	}
 
 	// Send the timeline notification
 	total, err := r.db.GetTeamPoints(request.Context(), team.ID)
@@ -291,7 +354,43 @@ func (r *rest) submitTeamFlag(writer http.ResponseWriter, request *http.Request,
 		Tags:       tags,
 	}
 
-	_ = r.eventSend("timeline", api.EventTimeline{TeamID: team.ID, Team: &team.TeamPut, Score: &score, Type: "score-updated"})
+	_ = r.eventSend(request.Context(), "timeline", api.EventTimeline{TeamID: team.ID, Team: &team.TeamPut, Division: team.Division, Score: &score, Type: "score-updated"})
+
+	// Award any first solvers bonus
+	if len(r.config.Scoring.FirstBloodBonus) > 0 && !adminFlag.Trap {
+		rank, bonus, err := r.db.AwardSolveBonus(request.Context(), team.ID, adminFlag.ID, r.config.Scoring.FirstBloodBonus)
+		if err != nil {
+			logger.Error("Failed to award the solve bonus", log15.Ctx{"error": err, "teamid": team.ID, "flagid": adminFlag.ID})
+		} else if bonus != 0 {
+			_ = r.eventSend(request.Context(), "first-blood", api.EventFirstBlood{TeamID: team.ID, Team: &team.TeamPut, Division: team.Division, Rank: rank, Value: bonus, Tags: tags})
+
+			err := r.sendScoreUpdate(request.Context(), team.ID, bonus, tags)
+			if err != nil {
+				logger.Error("Failed to send the score update", log15.Ctx{"error": err, "teamid": team.ID})
+			}
+
+			logger.Info("Solve bonus awarded", log15.Ctx{"teamid": team.ID, "flagid": adminFlag.ID, "rank": rank, "value": bonus})
+		}
+	}
+
+	// Update the other teams for dynamic flags
+	if r.config.Scoring.Mode == api.ScoringModeDynamic && adminFlag.IsDynamic() {
+		changes, err := r.db.RecomputeFlagScores(request.Context(), *adminFlag)
+		if err != nil {
+			logger.Error("Failed to recompute the flag value", log15.Ctx{"error": err, "flagid": adminFlag.ID})
+		}
+
+		for teamID, change := range changes {
+			err := r.sendScoreUpdate(request.Context(), teamID, change, tags)
+			if err != nil {
+				logger.Error("Failed to send the score update", log15.Ctx{"error": err, "teamid": teamID})
+			}
+		}
+
+		if len(changes) > 0 {
+			logger.Info("Dynamic flag value updated", log15.Ctx{"flagid": adminFlag.ID, "teams": len(changes)})
+		}
+	}
 
 	logger.Info("Correct flag submitted", log15.Ctx{"teamid": team.ID, "flagid": result.ID, "value": result.Value, "source": flag.Source, "flag": flag.Flag})
 	r.jsonResponse(result, writer, request)
@@ -332,9 +431,31 @@ func (r *rest) adminCreateFlag(writer http.ResponseWriter, request *http.Request
 		return
 	}
 
+	// Validate the input
+	err = validateFlag(newFlag.AdminFlagPut)
+	if err != nil {
+		logger.Warn("Invalid flag provided", log15.Ctx{"error": err})
+		r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	err = r.validateFlagRequires(request.Context(), -1, newFlag.Requires)
+	if err != nil {
+		logger.Warn("Invalid flag prerequisites provided", log15.Ctx{"error": err})
+		r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
 	// Attempt to update the database
 	id, err := r.db.CreateFlag(request.Context(), newFlag)
-	if err != nil {
+	if errors.Is(err, database.ErrFlagConflict) {
+		logger.Warn("Flag conflicts with an existing flag variant", log15.Ctx{"flag": newFlag.Flag})
+		r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	} else if err != nil {
 		logger.Error("Failed to create the flag", log15.Ctx{"error": err})
 		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
 
@@ -345,8 +466,8 @@ func (r *rest) adminCreateFlag(writer http.ResponseWriter, request *http.Request
 }
 
 func (r *rest) adminCreateFlags(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
-	// Decode the provided JSON input
-	newFlags := []api.AdminFlagPost{}
+	// Decode the provided JSON input (IDs are only used to resolve prerequisites)
+	newFlags := []api.AdminFlag{}
 
 	err := json.NewDecoder(request.Body).Decode(&newFlags)
 	if err != nil {
@@ -356,17 +477,41 @@ func (r *rest) adminCreateFlags(writer http.ResponseWriter, request *http.Reques
 		return
 	}
 
+	// Validate the input
 	for _, flag := range newFlags {
-		// Attempt to create the database record
-		id, err := r.db.CreateFlag(request.Context(), flag)
+		err := validateFlag(flag.AdminFlagPut)
 		if err != nil {
-			logger.Error("Failed to create the flag", log15.Ctx{"error": err})
-			r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+			logger.Warn("Invalid flag provided", log15.Ctx{"error": err, "flag": flag.Flag})
+			r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
 
 			return
 		}
+	}
+
+	err = r.validateImportRequires(request.Context(), newFlags)
+	if err != nil {
+		logger.Warn("Invalid flag prerequisites provided", log15.Ctx{"error": err})
+		r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	// Create the flags and their prerequisites
+	ids, err := r.db.ImportFlags(request.Context(), newFlags)
+	if errors.Is(err, database.ErrFlagConflict) {
+		logger.Warn("Flag conflicts with an existing flag variant")
+		r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	} else if err != nil {
+		logger.Error("Failed to import the flags", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
 
-		logger.Info("New flag defined", log15.Ctx{"id": id, "flag": flag.Flag, "value": flag.Value})
+	for i, flag := range newFlags {
+		logger.Info("New flag defined", log15.Ctx{"id": ids[i], "flag": flag.Flag, "value": flag.Value})
 	}
 }
 
@@ -411,6 +556,10 @@ func (r *rest) adminUpdateFlag(writer http.ResponseWriter, request *http.Request
 		return
 	}
 
+	// Check whether existing scores should be updated too
+	request.Body = http.MaxBytesReader(writer, request.Body, 1024*1024)
+	propagate := request.FormValue("propagate") == "1"
+
 	// Decode the provided JSON input
 	newFlag := api.AdminFlagPut{}
 
@@ -422,12 +571,41 @@ func (r *rest) adminUpdateFlag(writer http.ResponseWriter, request *http.Request
 		return
 	}
 
+	// Validate the input
+	err = validateFlag(newFlag)
+	if err != nil {
+		logger.Warn("Invalid flag provided", log15.Ctx{"error": err})
+		r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	err = r.validateFlagRequires(request.Context(), id, newFlag.Requires)
+	if err != nil {
+		logger.Warn("Invalid flag prerequisites provided", log15.Ctx{"error": err})
+		r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
 	// Attempt to update the database
-	err = r.db.UpdateFlag(request.Context(), id, newFlag)
+	changes := map[int64]int64{}
+
+	if propagate {
+		changes, err = r.db.UpdateFlagPropagate(request.Context(), id, newFlag, r.config.Scoring)
+	} else {
+		err = r.db.UpdateFlag(request.Context(), id, newFlag)
+	}
+
 	if errors.Is(err, sql.ErrNoRows) {
 		logger.Warn("Invalid flag ID provided", log15.Ctx{"id": idVar})
 		r.errorResponse(404, "Invalid flag ID provided", writer, request)
 
+		return
+	} else if errors.Is(err, database.ErrFlagConflict) {
+		logger.Warn("Flag conflicts with an existing flag variant", log15.Ctx{"id": id})
+		r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
 		return
 	} else if err != nil {
 		logger.Error("Failed to update the flag", log15.Ctx{"error": err})
@@ -436,7 +614,23 @@ func (r *rest) adminUpdateFlag(writer http.ResponseWriter, request *http.Request
 		return
 	}
 
-	logger.Info("Flag updated", log15.Ctx{"id": id, "flag": newFlag.Flag, "value": newFlag.Value})
+	// Notify the affected teams
+	tags := make(map[string]string)
+
+	for key, value := range newFlag.Tags {
+		if slices.Contains(r.config.Scoring.PublicTags, key) {
+			tags[key] = value
+		}
+	}
+
+	for teamID, change := range changes {
+		err := r.sendScoreUpdate(request.Context(), teamID, change, tags)
+		if err != nil {
+			logger.Error("Failed to send the score update", log15.Ctx{"error": err, "teamid": teamID})
+		}
+	}
+
+	logger.Info("Flag updated", log15.Ctx{"id": id, "flag": newFlag.Flag, "value": newFlag.Value, "propagate": propagate, "teams": len(changes)})
 }
 
 func (r *rest) adminDeleteFlag(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
@@ -451,6 +645,24 @@ func (r *rest) adminDeleteFlag(writer http.ResponseWriter, request *http.Request
 		return
 	}
 
+	// Check that no other flag depends on it
+	flags, err := r.db.GetFlags(request.Context())
+	if err != nil {
+		logger.Error("Failed to query the flag list", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	for _, flag := range flags {
+		if slices.Contains(flag.Requires, id) {
+			logger.Warn("Flag is required by another flag", log15.Ctx{"id": id, "required_by": flag.ID})
+			r.errorResponse(400, fmt.Sprintf("Flag is required by flag %d", flag.ID), writer, request)
+
+			return
+		}
+	}
+
 	// Attempt to get the DB record
 	err = r.db.DeleteFlag(request.Context(), id)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -492,3 +704,146 @@ func (r *rest) adminClearFlags(writer http.ResponseWriter, request *http.Request
 
 	logger.Info("All flags deleted")
 }
+
+func validateFlag(flag api.AdminFlagPut) error {
+	if flag.Decay < 0 {
+		return errors.New("flag decay can't be negative")
+	}
+
+	if flag.IsDynamic() && flag.MinimumValue > flag.InitialValue {
+		return errors.New("flag minimum value can't be higher than its initial value")
+	}
+
+	if !flag.ValidFrom.IsZero() && !flag.ValidUntil.IsZero() && !flag.ValidUntil.After(flag.ValidFrom) {
+		return errors.New("flag validity must end after it starts")
+	}
+
+	if flag.Trap && flag.IsDynamic() {
+		return errors.New("trap flags can't use dynamic scoring")
+	}
+
+	return nil
+}
+
+func (r *rest) validateFlagRequires(ctx context.Context, id int64, requires []int64) error {
+	if len(requires) == 0 {
+		return nil
+	}
+
+	flags, err := r.db.GetFlags(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Build the dependency graph with the new prerequisites
+	graph := map[int64][]int64{}
+	for _, flag := range flags {
+		graph[flag.ID] = flag.Requires
+	}
+
+	for _, required := range requires {
+		if required == id {
+			return errors.New("flag can't require itself")
+		}
+
+		_, ok := graph[required]
+		if !ok {
+			return fmt.Errorf("required flag %d doesn't exist", required)
+		}
+	}
+
+	if id < 0 {
+		// New flags can't be part of a cycle
+		return nil
+	}
+
+	graph[id] = requires
+
+	if flagGraphHasCycle(graph) {
+		return errors.New("flag prerequisites can't form a cycle")
+	}
+
+	return nil
+}
+
+func (r *rest) validateImportRequires(ctx context.Context, newFlags []api.AdminFlag) error {
+	flags, err := r.db.GetFlags(ctx)
+	if err != nil {
+		return err
+	}
+
+	existing := map[int64]bool{}
+	for _, flag := range flags {
+		existing[flag.ID] = true
+	}
+
+	// Build the dependency graph of the imported flags
+	graph := map[int64][]int64{}
+
+	for _, flag := range newFlags {
+		if flag.ID > 0 {
+			graph[flag.ID] = flag.Requires
+		}
+	}
+
+	for _, flag := range newFlags {
+		if len(flag.Requires) > 0 && flag.ID <= 0 {
+			return fmt.Errorf("flag %q has prerequisites but no ID", flag.Flag)
+		}
+
+		for _, required := range flag.Requires {
+			_, ok := graph[required]
+			if !ok && !existing[required] {
+				return fmt.Errorf("required flag %d doesn't exist", required)
+			}
+		}
+	}
+
+	if flagGraphHasCycle(graph) {
+		return errors.New("flag prerequisites can't form a cycle")
+	}
+
+	return nil
+}
+
+func flagGraphHasCycle(graph map[int64][]int64) bool {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := map[int64]int{}
+
+	var visit func(id int64) bool
+
+	visit = func(id int64) bool {
+		switch state[id] {
+		case visiting:
+			return true
+		case visited:
+			return false
+		default:
+		}
+
+		state[id] = visiting
+
+		for _, required := range graph[id] {
+			if visit(required) {
+				return true
+			}
+		}
+
+		state[id] = visited
+
+		return false
+	}
+
+	for id := range graph {
+		if state[id] == unvisited && visit(id) {
+			return true
+		}
+	}
+
+	return false
+}
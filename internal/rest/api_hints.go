@@ -0,0 +1,338 @@
+package rest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/nsec/askgod/api"
+)
+
+func (r *rest) getTeamHints(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	// Look for a matching team
+	team := r.requestTeam(writer, request, logger)
+	if team == nil {
+		return
+	}
+
+	// Get all the hints for the team
+	hints, err := r.db.GetTeamHints(request.Context(), team.ID)
+	if err != nil {
+		logger.Error("Failed to query the hint list", log15.Ctx{"error": err, "teamid": team.ID})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	r.jsonResponse(hints, writer, request)
+}
+
+func (r *rest) unlockTeamHint(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	// Check if read-only
+	if r.config.Scoring.ReadOnly {
+		r.errorResponse(403, "Hints can't be unlocked at this time", writer, request)
+
+		return
+	}
+
+	// Check the event schedule
+	state, _ := r.eventState()
+	if state != api.EventStateRunning {
+		r.errorResponse(403, "Hints can't be unlocked at this time", writer, request)
+
+		return
+	}
+
+	idVar := request.PathValue("id")
+
+	// Convert the provided id to int
+	id, err := strconv.ParseInt(idVar, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid hint ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(400, "Invalid hint ID provided", writer, request)
+
+		return
+	}
+
+	// Limit the request body size to avoid abuse.
+	request.Body = http.MaxBytesReader(writer, request.Body, 1024*1024)
+
+	// Decode the provided JSON input
+	hint := api.HintPost{}
+
+	err = json.NewDecoder(request.Body).Decode(&hint)
+	if err != nil {
+		logger.Warn("Malformed JSON provided", log15.Ctx{"error": err})
+		r.errorResponse(400, "Malformed JSON provided", writer, request)
+
+		return
+	}
+
+	source, ok := api.NormalizeSource(hint.Source)
+	if !ok {
+		logger.Warn("Invalid source value", log15.Ctx{"source": hint.Source})
+		r.errorResponse(400, "Invalid source value", writer, request)
+
+		return
+	}
+
+	hint.Source = source
+
+	// Look for a matching team
+	team := r.requestTeam(writer, request, logger)
+	if team == nil {
+		return
+	}
+
+	// Check that the team is configured
+	if team.Name == "" || team.Country == "" {
+		logger.Debug("Unconfigured team tried to unlock a hint", log15.Ctx{"teamid": team.ID})
+		r.errorResponse(400, "Team name and country are required to participate", writer, request)
+
+		return
+	}
+
+	// Unlock the hint
+	result, err := r.db.UnlockTeamHint(request.Context(), team.ID, id, hint)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Warn("Invalid hint ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(404, "Invalid hint ID provided", writer, request)
+
+		return
+	} else if errors.Is(err, os.ErrExist) {
+		logger.Info("The hint was already unlocked", log15.Ctx{"teamid": team.ID, "hintid": id})
+		r.errorResponse(400, "The hint was already unlocked", writer, request)
+
+		return
+	} else if err != nil {
+		logger.Error("Failed to unlock the hint", log15.Ctx{"error": err, "teamid": team.ID, "hintid": id})
+		r.errorResponse(500, "Internal Server Error", writer, request)
+
+		return
+	}
+
+	// Send the notifications
+	adminFlag, err := r.db.GetFlag(request.Context(), result.FlagID)
+	if err != nil {
+		logger.Error("Failed to get the flag", log15.Ctx{"error": err, "flagid": result.FlagID})
+	} else {
+		_ = r.eventSend(request.Context(), "flags", api.EventFlag{Team: *team, Flag: adminFlag, Value: -result.Cost, Type: "hint", Source: hint.Source})
+
+		tags := make(map[string]string)
+
+		for key, value := range adminFlag.Tags {
+			if slices.Contains(r.config.Scoring.PublicTags, key) {
+				tags[key] = value
+			}
+		}
+
+		err = r.sendScoreUpdate(request.Context(), team.ID, -result.Cost, tags)
+		if err != nil {
+			logger.Error("Failed to send the score update", log15.Ctx{"error": err, "teamid": team.ID})
+		}
+	}
+
+	logger.Info("Hint unlocked", log15.Ctx{"teamid": team.ID, "hintid": result.ID, "flagid": result.FlagID, "cost": result.Cost, "source": hint.Source})
+	r.jsonResponse(result, writer, request)
+}
+
+func (r *rest) adminGetHints(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	// Get all the hints from the database
+	hints, err := r.db.GetHints(request.Context())
+	if err != nil {
+		logger.Error("Failed to query the hint list", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	r.jsonResponse(hints, writer, request)
+}
+
+func (r *rest) adminCreateHint(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	// Decode the provided JSON input
+	newHint := api.AdminHintPost{}
+
+	err := json.NewDecoder(request.Body).Decode(&newHint)
+	if err != nil {
+		logger.Warn("Malformed JSON provided", log15.Ctx{"error": err})
+		r.errorResponse(400, "Malformed JSON provided", writer, request)
+
+		return
+	}
+
+	// Validate the input
+	err = r.validateHint(request.Context(), newHint.AdminHintPut)
+	if err != nil {
+		logger.Warn("Invalid hint provided", log15.Ctx{"error": err})
+		r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	// Attempt to update the database
+	id, err := r.db.CreateHint(request.Context(), newHint)
+	if err != nil {
+		logger.Error("Failed to create the hint", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	logger.Info("New hint defined", log15.Ctx{"id": id, "flagid": newHint.FlagID, "cost": newHint.Cost})
+}
+
+func (r *rest) adminGetHint(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	idVar := request.PathValue("id")
+
+	// Convert the provided id to int
+	id, err := strconv.ParseInt(idVar, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid hint ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(400, "Invalid hint ID provided", writer, request)
+
+		return
+	}
+
+	// Attempt to get the DB record
+	hint, err := r.db.GetHint(request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Warn("Invalid hint ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(404, "Invalid hint ID provided", writer, request)
+
+		return
+	} else if err != nil {
+		logger.Error("Failed to get the hint", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	r.jsonResponse(hint, writer, request)
+}
+
+func (r *rest) adminUpdateHint(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	idVar := request.PathValue("id")
+
+	// Convert the provided id to int
+	id, err := strconv.ParseInt(idVar, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid hint ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(400, "Invalid hint ID provided", writer, request)
+
+		return
+	}
+
+	// Decode the provided JSON input
+	newHint := api.AdminHintPut{}
+
+	err = json.NewDecoder(request.Body).Decode(&newHint)
+	if err != nil {
+		logger.Warn("Malformed JSON provided", log15.Ctx{"error": err})
+		r.errorResponse(400, "Malformed JSON provided", writer, request)
+
+		return
+	}
+
+	// Validate the input
+	err = r.validateHint(request.Context(), newHint)
+	if err != nil {
+		logger.Warn("Invalid hint provided", log15.Ctx{"error": err})
+		r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	// Attempt to update the database
+	err = r.db.UpdateHint(request.Context(), id, newHint)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Warn("Invalid hint ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(404, "Invalid hint ID provided", writer, request)
+
+		return
+	} else if err != nil {
+		logger.Error("Failed to update the hint", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	logger.Info("Hint updated", log15.Ctx{"id": id, "flagid": newHint.FlagID, "cost": newHint.Cost})
+}
+
+func (r *rest) adminDeleteHint(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	idVar := request.PathValue("id")
+
+	// Convert the provided id to int
+	id, err := strconv.ParseInt(idVar, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid hint ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(400, "Invalid hint ID provided", writer, request)
+
+		return
+	}
+
+	// Attempt to get the DB record
+	err = r.db.DeleteHint(request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Warn("Invalid hint ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(404, "Invalid hint ID provided", writer, request)
+
+		return
+	} else if err != nil {
+		logger.Error("Failed to delete the hint", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	logger.Info("Hint deleted", log15.Ctx{"id": id})
+}
+
+func (r *rest) adminClearHints(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	request.Body = http.MaxBytesReader(writer, request.Body, 1024*1024)
+
+	emptyVar := request.FormValue("empty")
+
+	// Confirm the user is sure about it
+	if emptyVar != "1" {
+		logger.Warn("Hints clear requested without empty=1")
+		r.errorResponse(400, "Hints clear requested without empty=1", writer, request)
+
+		return
+	}
+
+	// Clear the database entries
+	err := r.db.ClearHints(request.Context())
+	if err != nil {
+		logger.Error("Failed to clear all hints", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	logger.Info("All hints deleted")
+}
+
+func (r *rest) validateHint(ctx context.Context, hint api.AdminHintPut) error {
+	if hint.Cost < 0 {
+		return errors.New("hint cost can't be negative")
+	}
+
+	_, err := r.db.GetFlag(ctx, hint.FlagID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("hint references unknown flag %d", hint.FlagID)
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
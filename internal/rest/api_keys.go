@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/nsec/askgod/api"
+)
+
+func (r *rest) adminGetKeys(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	// Get all the keys from the database
+	keys, err := r.db.GetAdminKeys(request.Context())
+	if err != nil {
+		logger.Error("Failed to query the admin key list", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	r.jsonResponse(keys, writer, request)
+}
+
+func (r *rest) adminCreateKey(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	// Decode the provided JSON input
+	newKey := api.AdminKeyPost{}
+
+	err := json.NewDecoder(request.Body).Decode(&newKey)
+	if err != nil {
+		logger.Warn("Malformed JSON provided", log15.Ctx{"error": err})
+		r.errorResponse(400, "Malformed JSON provided", writer, request)
+
+		return
+	}
+
+	// Validate the input
+	if newKey.Name == "" {
+		logger.Warn("Invalid admin key provided", log15.Ctx{"error": "missing name"})
+		r.errorResponse(400, "Admin key name is required", writer, request)
+
+		return
+	}
+
+	if !slices.Contains([]string{api.AdminRoleAdmin, api.AdminRoleAuditor, api.AdminRoleFlagManager, api.AdminRoleScoreManager}, newKey.Role) {
+		logger.Warn("Invalid admin key provided", log15.Ctx{"role": newKey.Role})
+		r.errorResponse(400, fmt.Sprintf("Invalid admin role: %s", newKey.Role), writer, request)
+
+		return
+	}
+
+	// Attempt to create the database record
+	key, err := r.db.CreateAdminKey(request.Context(), newKey)
+	if err != nil {
+		logger.Error("Failed to create the admin key", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	logger.Info("New admin key issued", log15.Ctx{"id": key.ID, "name": key.Name, "role": key.Role})
+
+	r.jsonResponse(key, writer, request)
+}
+
+func (r *rest) adminDeleteKey(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	idVar := request.PathValue("id")
+
+	// Convert the provided id to int
+	id, err := strconv.ParseInt(idVar, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid admin key ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(400, "Invalid admin key ID provided", writer, request)
+
+		return
+	}
+
+	// Attempt to delete the DB record
+	err = r.db.DeleteAdminKey(request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Warn("Invalid admin key ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(404, "Invalid admin key ID provided", writer, request)
+
+		return
+	} else if err != nil {
+		logger.Error("Failed to revoke the admin key", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	logger.Info("Admin key revoked", log15.Ctx{"id": id})
+}
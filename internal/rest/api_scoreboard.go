@@ -2,8 +2,6 @@
 package rest
 
 import (
-	"database/sql"
-	"errors"
 	"net/http"
 	"slices"
 
@@ -20,8 +18,17 @@ func (r *rest) getScoreboard(writer http.ResponseWriter, request *http.Request,
 		return
 	}
 
+	// Parse the filters
+	filter, err := r.scoreFilter(request)
+	if err != nil {
+		logger.Warn("Invalid scoreboard filter", log15.Ctx{"error": err})
+		r.errorResponse(400, "Invalid filter", writer, request)
+
+		return
+	}
+
 	// Get the full scoreboard
-	scoreboard, err := r.db.GetScoreboard(request.Context())
+	scoreboard, err := r.db.GetScoreboard(request.Context(), filter, r.config.Scoring.Tiebreak)
 	if err != nil {
 		logger.Error("Failed to get the scoreboard", log15.Ctx{"error": err})
 		r.errorResponse(500, "Internal Server Error", writer, request)
@@ -31,28 +38,11 @@ func (r *rest) getScoreboard(writer http.ResponseWriter, request *http.Request,
 
 	// Filter the results
 	if (r.config.Scoring.HideOthers || len(r.hiddenTeams) > 0) && !r.hasAccess("admin", request) {
-		// Extract the client IP
-		ip, err := r.getIP(request)
-		if err != nil {
-			logger.Error("Failed to get the client's IP", log15.Ctx{"error": err})
-			r.errorResponse(500, "Internal Server Error", writer, request)
-
-			return
-		}
-
 		// Look for a matching team
 		var team *api.AdminTeam
 		if r.hasAccess("team", request) {
-			team, err = r.db.GetTeamForIP(request.Context(), *ip)
-			if errors.Is(err, sql.ErrNoRows) {
-				logger.Warn("No team found for IP", log15.Ctx{"ip": ip.String()})
-				r.errorResponse(404, "No team found for IP", writer, request)
-
-				return
-			} else if err != nil {
-				logger.Error("Failed to get the team", log15.Ctx{"error": err})
-				r.errorResponse(500, "Internal Server Error", writer, request)
-
+			team = r.requestTeam(writer, request, logger)
+			if team == nil {
 				return
 			}
 		}
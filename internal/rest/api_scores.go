@@ -1,11 +1,13 @@
 package rest
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
@@ -59,6 +61,16 @@ func (r *rest) adminCreateScore(writer http.ResponseWriter, request *http.Reques
 
 	newScore.Source = source
 
+	scoreType, ok := normalizeScoreType(newScore.Type)
+	if !ok {
+		logger.Warn("Invalid score type", log15.Ctx{"type": newScore.Type})
+		r.errorResponse(400, "Invalid score type", writer, request)
+
+		return
+	}
+
+	newScore.Type = scoreType
+
 	r.adminCreateScoreCommon(writer, request, logger, newScore)
 }
 
@@ -85,6 +97,16 @@ func (r *rest) adminCreateScores(writer http.ResponseWriter, request *http.Reque
 
 		newScores[i].Source = source
 
+		scoreType, ok := normalizeScoreType(score.Type)
+		if !ok {
+			logger.Warn("Invalid score type", log15.Ctx{"type": score.Type})
+			r.errorResponse(400, "Invalid score type", writer, request)
+
+			return
+		}
+
+		newScores[i].Type = scoreType
+
 		if !r.adminCreateScoreCommon(writer, request, logger, newScores[i]) {
 			return
 		}
@@ -119,7 +141,7 @@ func (r *rest) adminCreateScoreCommon(writer http.ResponseWriter, request *http.
 		return false
 	}
 
-	_ = r.eventSend("flags", api.EventFlag{Team: *team, Flag: flag, Input: flag.Flag, Value: newScore.Value, Type: "valid", Source: newScore.Source})
+	_ = r.eventSend(request.Context(), "flags", api.EventFlag{Team: *team, Flag: flag, Input: flag.Flag, Value: newScore.Value, Type: "valid", Source: newScore.Source})
 
 	// Send the timeline notification
 	total, err := r.db.GetTeamPoints(request.Context(), newScore.TeamID)
@@ -136,13 +158,46 @@ func (r *rest) adminCreateScoreCommon(writer http.ResponseWriter, request *http.
 		Total:      total,
 	}
 
-	_ = r.eventSend("timeline", api.EventTimeline{TeamID: team.ID, Team: &team.TeamPut, Score: &score, Type: "score-updated"})
+	_ = r.eventSend(request.Context(), "timeline", api.EventTimeline{TeamID: team.ID, Team: &team.TeamPut, Division: team.Division, Score: &score, Type: "score-updated"})
 
 	logger.Info("New score entry defined", log15.Ctx{"id": id, "flagid": newScore.FlagID, "teamid": newScore.TeamID, "value": newScore.Value, "source": newScore.Source})
 
 	return true
 }
 
+func normalizeScoreType(scoreType string) (string, bool) {
+	if scoreType == "" {
+		return api.ScoreTypeSolve, true
+	}
+
+	if slices.Contains([]string{api.ScoreTypeSolve, api.ScoreTypeBonus}, scoreType) {
+		return scoreType, true
+	}
+
+	return scoreType, false
+}
+
+func (r *rest) sendScoreUpdate(ctx context.Context, teamID int64, value int64, tags map[string]string) error {
+	team, err := r.db.GetTeam(ctx, teamID)
+	if err != nil {
+		return err
+	}
+
+	total, err := r.db.GetTeamPoints(ctx, teamID)
+	if err != nil {
+		return err
+	}
+
+	score := api.TimelineEntryScore{
+		SubmitTime: time.Now(),
+		Value:      value,
+		Total:      total,
+		Tags:       tags,
+	}
+
+	return r.eventSend(ctx, "timeline", api.EventTimeline{TeamID: team.ID, Team: &team.TeamPut, Division: team.Division, Score: &score, Type: "score-updated"})
+}
+
 func (r *rest) adminGetScore(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
 	idVar := request.PathValue("id")
 
@@ -256,7 +311,7 @@ func (r *rest) adminUpdateScore(writer http.ResponseWriter, request *http.Reques
 		Total:      totalAfter,
 	}
 
-	_ = r.eventSend("timeline", api.EventTimeline{TeamID: team.ID, Team: &team.TeamPut, Score: &score, Type: "score-updated"})
+	_ = r.eventSend(request.Context(), "timeline", api.EventTimeline{TeamID: team.ID, Team: &team.TeamPut, Division: team.Division, Score: &score, Type: "score-updated"})
 
 	logger.Info("Score entry updated", log15.Ctx{"id": id, "value": newScore.Value})
 }
@@ -334,7 +389,7 @@ func (r *rest) adminDeleteScore(writer http.ResponseWriter, request *http.Reques
 		Total:      totalAfter,
 	}
 
-	_ = r.eventSend("timeline", api.EventTimeline{TeamID: team.ID, Team: &team.TeamPut, Score: &score, Type: "score-updated"})
+	_ = r.eventSend(request.Context(), "timeline", api.EventTimeline{TeamID: team.ID, Team: &team.TeamPut, Division: team.Division, Score: &score, Type: "score-updated"})
 
 	logger.Info("Score entry deleted", log15.Ctx{"id": id})
 }
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"math"
 	"net/http"
 
 	"github.com/inconshreveable/log15"
@@ -9,16 +10,23 @@ import (
 )
 
 func (r *rest) getStatus(writer http.ResponseWriter, request *http.Request, _ log15.Logger) {
+	state, countdown := r.eventState()
+
 	resp := api.Status{
-		IsAdmin:   r.hasAccess("admin", request),
-		IsTeam:    r.hasAccess("team", request),
-		IsGuest:   r.hasAccess("guest", request),
-		EventName: r.config.Scoring.EventName,
+		IsAdmin:        r.hasAccess("admin", request),
+		IsTeam:         r.hasAccess("team", request),
+		IsGuest:        r.hasAccess("guest", request),
+		EventName:      r.config.Scoring.EventName,
+		EventStart:     r.config.Scoring.StartTime,
+		EventEnd:       r.config.Scoring.EndTime,
+		EventState:     state,
+		EventCountdown: int64(math.Ceil(countdown.Seconds())),
 		Flags: api.StatusFlags{
 			TeamSelfRegister: r.config.Teams.SelfRegister,
 			TeamSelfUpdate:   r.config.Teams.SelfUpdate,
-			BoardReadOnly:    r.config.Scoring.ReadOnly,
+			BoardReadOnly:    r.config.Scoring.ReadOnly || state != api.EventStateRunning,
 			BoardHideOthers:  r.config.Scoring.HideOthers,
+			BoardTiebreak:    r.config.Scoring.Tiebreak,
 		},
 	}
 
@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/nsec/askgod/api"
+	"github.com/nsec/askgod/internal/database"
+)
+
+func (r *rest) adminGetSubmissions(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	filter := database.SubmissionFilter{
+		IP:      request.FormValue("ip"),
+		Outcome: request.FormValue("outcome"),
+		Source:  request.FormValue("source"),
+	}
+
+	// Parse the numeric filters
+	for key, target := range map[string]*int64{"team_id": &filter.TeamID, "flag_id": &filter.FlagID, "limit": &filter.Limit} {
+		value := request.FormValue(key)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 0 {
+			logger.Warn("Invalid submission filter", log15.Ctx{"key": key, "value": value})
+			r.errorResponse(400, "Invalid filter", writer, request)
+
+			return
+		}
+
+		*target = parsed
+	}
+
+	// Parse the time filters
+	for key, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		value := request.FormValue(key)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			logger.Warn("Invalid submission filter", log15.Ctx{"key": key, "value": value})
+			r.errorResponse(400, "Invalid filter", writer, request)
+
+			return
+		}
+
+		*target = parsed
+	}
+
+	// Get the submissions from the database
+	submissions, err := r.db.GetSubmissions(request.Context(), filter)
+	if err != nil {
+		logger.Error("Failed to query the submission list", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	r.jsonResponse(submissions, writer, request)
+}
+
+func (r *rest) adminGetNearMisses(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	// Get the report from the database
+	nearMisses, err := r.db.GetNearMisses(request.Context())
+	if err != nil {
+		logger.Error("Failed to query the near-miss report", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	r.jsonResponse(nearMisses, writer, request)
+}
+
+// recordSubmission keeps a permanent record of a flag submission attempt.
+func (r *rest) recordSubmission(ctx context.Context, logger log15.Logger, teamID int64, ip net.IP, flag api.FlagPost, flagID int64, outcome string) {
+	_, err := r.db.CreateSubmission(ctx, api.AdminSubmission{
+		TeamID:  teamID,
+		FlagID:  flagID,
+		IP:      ip.String(),
+		Input:   flag.Flag,
+		Outcome: outcome,
+		Source:  flag.Source,
+	})
+	if err != nil {
+		logger.Error("Failed to record the submission", log15.Ctx{"error": err, "teamid": teamID, "outcome": outcome})
+	}
+}
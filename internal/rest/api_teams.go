@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -14,29 +15,13 @@ import (
 	"github.com/inconshreveable/log15"
 
 	"github.com/nsec/askgod/api"
+	"github.com/nsec/askgod/internal/database"
 )
 
 func (r *rest) getTeam(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
-	// Extract the client IP
-	ip, err := r.getIP(request)
-	if err != nil {
-		logger.Error("Failed to get the client's IP", log15.Ctx{"error": err})
-		r.errorResponse(500, "Internal Server Error", writer, request)
-
-		return
-	}
-
 	// Look for a matching team
-	record, err := r.db.GetTeamForIP(request.Context(), *ip)
-	if errors.Is(err, sql.ErrNoRows) {
-		logger.Warn("No team found for IP", log15.Ctx{"ip": ip.String()})
-		r.errorResponse(404, "No team found for IP", writer, request)
-
-		return
-	} else if err != nil {
-		logger.Error("Failed to get the team", log15.Ctx{"error": err})
-		r.errorResponse(500, "Internal Server Error", writer, request)
-
+	record := r.requestTeam(writer, request, logger)
+	if record == nil {
 		return
 	}
 
@@ -46,6 +31,7 @@ func (r *rest) getTeam(writer http.ResponseWriter, request *http.Request, logger
 	team.Name = record.Name
 	team.Country = record.Country
 	team.Website = record.Website
+	team.Division = record.Division
 
 	r.jsonResponse(team, writer, request)
 }
@@ -110,26 +96,9 @@ func (r *rest) updateTeam(writer http.ResponseWriter, request *http.Request, log
 		newTeam.Website = u.String()
 	}
 
-	// Extract the client IP
-	ip, err := r.getIP(request)
-	if err != nil {
-		logger.Error("Failed to get the client's IP", log15.Ctx{"error": err})
-		r.errorResponse(500, "Internal Server Error", writer, request)
-
-		return
-	}
-
 	// Look for a matching team
-	team, err := r.db.GetTeamForIP(request.Context(), *ip)
-	if errors.Is(err, sql.ErrNoRows) {
-		logger.Warn("No team found for IP", log15.Ctx{"ip": ip.String()})
-		r.errorResponse(404, "No team found for IP", writer, request)
-
-		return
-	} else if err != nil {
-		logger.Error("Failed to get the team", log15.Ctx{"error": err})
-		r.errorResponse(500, "Internal Server Error", writer, request)
-
+	team := r.requestTeam(writer, request, logger)
+	if team == nil {
 		return
 	}
 
@@ -165,6 +134,7 @@ func (r *rest) updateTeam(writer http.ResponseWriter, request *http.Request, log
 	newRecord.Notes = team.Notes
 	newRecord.Subnets = team.Subnets
 	newRecord.Tags = team.Tags
+	newRecord.Division = team.Division
 
 	// Attempt to update the database
 	err = r.db.UpdateTeam(request.Context(), team.ID, newRecord)
@@ -175,7 +145,8 @@ func (r *rest) updateTeam(writer http.ResponseWriter, request *http.Request, log
 		return
 	}
 
-	_ = r.eventSend("timeline", api.EventTimeline{TeamID: team.ID, Team: &newRecord.TeamPut, Type: "team-updated"})
+	r.teamsUpdated(request.Context())
+	_ = r.eventSend(request.Context(), "timeline", api.EventTimeline{TeamID: team.ID, Team: &newRecord.TeamPut, Division: newRecord.Division, Type: "team-updated"})
 	logger.Info("Team updated", log15.Ctx{"id": team.ID, "name": newRecord.Name, "country": newRecord.Country, "website": newRecord.Website})
 }
 
@@ -216,14 +187,20 @@ func (r *rest) adminCreateTeam(writer http.ResponseWriter, request *http.Request
 
 	// Attempt to create the database record
 	id, err := r.db.CreateTeam(request.Context(), newTeam)
-	if err != nil {
+	if errors.Is(err, database.ErrTeamSubnets) {
+		logger.Warn("Invalid team subnets provided", log15.Ctx{"error": err})
+		r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	} else if err != nil {
 		logger.Error("Failed to create the team", log15.Ctx{"error": err})
 		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
 
 		return
 	}
 
-	_ = r.eventSend("timeline", api.EventTimeline{TeamID: id, Team: &newTeam.TeamPut, Type: "team-added"})
+	r.teamsUpdated(request.Context())
+	_ = r.eventSend(request.Context(), "timeline", api.EventTimeline{TeamID: id, Team: &newTeam.TeamPut, Division: newTeam.Division, Type: "team-added"})
 	logger.Info("New team defined", log15.Ctx{"id": id, "subnets": newTeam.Subnets})
 }
 
@@ -242,14 +219,20 @@ func (r *rest) adminCreateTeams(writer http.ResponseWriter, request *http.Reques
 	for _, team := range newTeams {
 		// Attempt to create the database record
 		id, err := r.db.CreateTeam(request.Context(), team)
-		if err != nil {
+		if errors.Is(err, database.ErrTeamSubnets) {
+			logger.Warn("Invalid team subnets provided", log15.Ctx{"error": err})
+			r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
+			return
+		} else if err != nil {
 			logger.Error("Failed to create the team", log15.Ctx{"error": err})
 			r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
 
 			return
 		}
 
-		_ = r.eventSend("timeline", api.EventTimeline{TeamID: id, Team: &team.TeamPut, Type: "team-added"})
+		r.teamsUpdated(request.Context())
+		_ = r.eventSend(request.Context(), "timeline", api.EventTimeline{TeamID: id, Team: &team.TeamPut, Division: team.Division, Type: "team-added"})
 		logger.Info("New team defined", log15.Ctx{"id": id, "subnets": team.Subnets})
 	}
 }
@@ -312,6 +295,11 @@ func (r *rest) adminUpdateTeam(writer http.ResponseWriter, request *http.Request
 		logger.Warn("Invalid team ID provided", log15.Ctx{"id": idVar})
 		r.errorResponse(404, "Invalid team ID provided", writer, request)
 
+		return
+	} else if errors.Is(err, database.ErrTeamSubnets) {
+		logger.Warn("Invalid team subnets provided", log15.Ctx{"error": err})
+		r.errorResponse(400, fmt.Sprintf("%v", err), writer, request)
+
 		return
 	} else if err != nil {
 		logger.Error("Failed to update the team", log15.Ctx{"error": err})
@@ -320,7 +308,8 @@ func (r *rest) adminUpdateTeam(writer http.ResponseWriter, request *http.Request
 		return
 	}
 
-	_ = r.eventSend("timeline", api.EventTimeline{TeamID: id, Team: &newTeam.TeamPut, Type: "team-updated"})
+	r.teamsUpdated(request.Context())
+	_ = r.eventSend(request.Context(), "timeline", api.EventTimeline{TeamID: id, Team: &newTeam.TeamPut, Division: newTeam.Division, Type: "team-updated"})
 	logger.Info("Team updated", log15.Ctx{"id": id, "name": newTeam.Name, "country": newTeam.Country, "website": newTeam.Website})
 }
 
@@ -350,7 +339,8 @@ func (r *rest) adminDeleteTeam(writer http.ResponseWriter, request *http.Request
 		return
 	}
 
-	_ = r.eventSend("timeline", api.EventTimeline{TeamID: id, Type: "team-removed"})
+	r.teamsUpdated(request.Context())
+	_ = r.eventSend(request.Context(), "timeline", api.EventTimeline{TeamID: id, Type: "team-removed"})
 	logger.Info("Team deleted", log15.Ctx{"id": id})
 }
 
@@ -376,5 +366,11 @@ func (r *rest) adminClearTeams(writer http.ResponseWriter, request *http.Request
 		return
 	}
 
+	r.teamsUpdated(request.Context())
 	logger.Info("All teams deleted")
 }
+
+// teamsUpdated tells the cluster peers to refresh their view of the teams.
+func (r *rest) teamsUpdated(ctx context.Context) {
+	_ = r.eventSend(ctx, "internal", api.EventInternal{Type: "teams-updated"})
+}
@@ -0,0 +1,164 @@
+package rest
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/nsec/askgod/api"
+)
+
+func (r *rest) adminGetTeamTokens(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	idVar := request.PathValue("id")
+
+	// Convert the provided id to int
+	id, err := strconv.ParseInt(idVar, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid team ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(400, "Invalid team ID provided", writer, request)
+
+		return
+	}
+
+	// Get all the tokens from the database
+	tokens, err := r.db.GetTeamTokens(request.Context(), id)
+	if err != nil {
+		logger.Error("Failed to query the team token list", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	r.jsonResponse(tokens, writer, request)
+}
+
+func (r *rest) adminCreateTeamToken(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	idVar := request.PathValue("id")
+
+	// Convert the provided id to int
+	id, err := strconv.ParseInt(idVar, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid team ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(400, "Invalid team ID provided", writer, request)
+
+		return
+	}
+
+	// Get the team
+	team, err := r.db.GetTeam(request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Warn("Invalid team ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(404, "Invalid team ID provided", writer, request)
+
+		return
+	} else if err != nil {
+		logger.Error("Failed to get the team", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	// Decode the provided JSON input
+	newToken := api.AdminTeamTokenPost{}
+
+	err = json.NewDecoder(request.Body).Decode(&newToken)
+	if err != nil {
+		logger.Warn("Malformed JSON provided", log15.Ctx{"error": err})
+		r.errorResponse(400, "Malformed JSON provided", writer, request)
+
+		return
+	}
+
+	// Attempt to create the database record
+	token, err := r.db.CreateTeamToken(request.Context(), team.ID, newToken)
+	if err != nil {
+		logger.Error("Failed to create the team token", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	logger.Info("New team token issued", log15.Ctx{"id": token.ID, "teamid": team.ID, "description": token.Description})
+
+	r.jsonResponse(token, writer, request)
+}
+
+func (r *rest) adminClearTeamTokens(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	idVar := request.PathValue("id")
+
+	// Convert the provided id to int
+	id, err := strconv.ParseInt(idVar, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid team ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(400, "Invalid team ID provided", writer, request)
+
+		return
+	}
+
+	request.Body = http.MaxBytesReader(writer, request.Body, 1024*1024)
+
+	emptyVar := request.FormValue("empty")
+
+	// Confirm the user is sure about it
+	if emptyVar != "1" {
+		logger.Warn("Team tokens clear requested without empty=1")
+		r.errorResponse(400, "Team tokens clear requested without empty=1", writer, request)
+
+		return
+	}
+
+	// Clear the database entries
+	err = r.db.ClearTeamTokens(request.Context(), id)
+	if err != nil {
+		logger.Error("Failed to clear the team tokens", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	logger.Info("All team tokens revoked", log15.Ctx{"teamid": id})
+}
+
+func (r *rest) adminDeleteTeamToken(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	idVar := request.PathValue("id")
+
+	// Convert the provided id to int
+	id, err := strconv.ParseInt(idVar, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid team ID provided", log15.Ctx{"id": idVar})
+		r.errorResponse(400, "Invalid team ID provided", writer, request)
+
+		return
+	}
+
+	tokenVar := request.PathValue("tokenid")
+
+	tokenID, err := strconv.ParseInt(tokenVar, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid token ID provided", log15.Ctx{"id": tokenVar})
+		r.errorResponse(400, "Invalid token ID provided", writer, request)
+
+		return
+	}
+
+	// Attempt to delete the DB record
+	err = r.db.DeleteTeamToken(request.Context(), id, tokenID)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Warn("Invalid token ID provided", log15.Ctx{"id": tokenVar})
+		r.errorResponse(404, "Invalid token ID provided", writer, request)
+
+		return
+	} else if err != nil {
+		logger.Error("Failed to revoke the team token", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	logger.Info("Team token revoked", log15.Ctx{"id": tokenID, "teamid": id})
+}
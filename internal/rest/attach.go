@@ -2,6 +2,7 @@ package rest
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"net/http"
 	"net/url"
@@ -17,7 +18,8 @@ import (
 var clusterPeers []string
 
 // AttachFunctions attaches all the REST API functions to the provided router.
-func AttachFunctions(ctx context.Context, conf *config.Config, router *http.ServeMux, db *database.DB, logger log15.Logger) error {
+// It returns a function checking whether an IP belongs to the trusted proxies of the current configuration.
+func AttachFunctions(ctx context.Context, conf *config.Config, router *http.ServeMux, db *database.DB, logger log15.Logger) (func(net.IP) bool, error) {
 	r := rest{
 		config: conf,
 		db:     db,
@@ -28,7 +30,34 @@ func AttachFunctions(ctx context.Context, conf *config.Config, router *http.Serv
 	// Update the list of hidden teams
 	err := r.configHiddenTeams(ctx)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// Check the rate limit configuration
+	err = validateRateLimit(r.config.Scoring)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limit configuration: %w", err)
+	}
+
+	// Compile the subnet ACL, refreshing it on configuration file changes
+	err = r.configACL()
+	if err != nil {
+		return nil, fmt.Errorf("invalid subnet configuration: %w", err)
+	}
+
+	err = conf.RegisterHandler(func(_ *config.Config) {
+		err := r.configACL()
+		if err != nil {
+			r.logger.Error("Invalid subnet configuration in the configuration file, keeping the previous one", log15.Ctx{"error": err})
+		}
+
+		err = validateRateLimit(r.config.Scoring)
+		if err != nil {
+			r.logger.Error("Invalid rate limit configuration", log15.Ctx{"error": err})
+		}
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Guest API
@@ -44,18 +73,36 @@ func AttachFunctions(ctx context.Context, conf *config.Config, router *http.Serv
 	r.registerEndpoint("/1.0/team", "team", r.getTeam, nil, r.updateTeam, nil)
 	r.registerEndpoint("/1.0/team/flags", "team", r.getTeamFlags, r.submitTeamFlag, nil, nil)
 	r.registerEndpoint("/1.0/team/flags/{id}", "team", r.getTeamFlag, nil, r.updateTeamFlag, nil)
+	r.registerEndpoint("/1.0/team/hints", "team", r.getTeamHints, nil, nil, nil)
+	r.registerEndpoint("/1.0/team/hints/{id}", "team", nil, r.unlockTeamHint, nil, nil)
 
-	// Admin API
+	// Admin API (the scope following the level restricts which admin roles may make changes)
+	r.registerEndpoint("/1.0/audit", "admin:audit", r.adminGetAudit, nil, nil, nil)
 	r.registerEndpoint("/1.0/config", "admin", r.getConfig, nil, r.updateConfig, nil)
+	r.registerEndpoint("/1.0/keys", "admin", r.adminGetKeys, r.adminCreateKey, nil, nil)
+	r.registerEndpoint("/1.0/keys/{id}", "admin", nil, nil, nil, r.adminDeleteKey)
+	r.registerEndpoint("/1.0/scoreboard/reveal", "admin:scores", nil, r.revealScoreboard, nil, nil)
 
-	r.registerEndpoint("/1.0/flags", "admin", r.adminGetFlags, r.adminCreateFlag, nil, r.adminClearFlags)
-	r.registerEndpoint("/1.0/flags/{id}", "admin", r.adminGetFlag, nil, r.adminUpdateFlag, r.adminDeleteFlag)
+	r.registerEndpoint("/1.0/adjustments", "admin:scores", r.adminGetAdjustments, r.adminCreateAdjustment, nil, r.adminClearAdjustments)
+	r.registerEndpoint("/1.0/adjustments/{id}", "admin:scores", r.adminGetAdjustment, nil, r.adminUpdateAdjustment, r.adminDeleteAdjustment)
 
-	r.registerEndpoint("/1.0/scores", "admin", r.adminGetScores, r.adminCreateScore, nil, r.adminClearScores)
-	r.registerEndpoint("/1.0/scores/{id}", "admin", r.adminGetScore, nil, r.adminUpdateScore, r.adminDeleteScore)
+	r.registerEndpoint("/1.0/flags", "admin:flags", r.adminGetFlags, r.adminCreateFlag, nil, r.adminClearFlags)
+	r.registerEndpoint("/1.0/flags/{id}", "admin:flags", r.adminGetFlag, nil, r.adminUpdateFlag, r.adminDeleteFlag)
+	r.registerEndpoint("/1.0/flags/{id}/variants", "admin:flags", r.adminGetFlagVariants, r.adminCreateFlagVariants, nil, r.adminClearFlagVariants)
 
-	r.registerEndpoint("/1.0/teams", "admin", r.adminGetTeams, r.adminCreateTeam, nil, r.adminClearTeams)
-	r.registerEndpoint("/1.0/teams/{id}", "admin", r.adminGetTeam, nil, r.adminUpdateTeam, r.adminDeleteTeam)
+	r.registerEndpoint("/1.0/hints", "admin:flags", r.adminGetHints, r.adminCreateHint, nil, r.adminClearHints)
+	r.registerEndpoint("/1.0/hints/{id}", "admin:flags", r.adminGetHint, nil, r.adminUpdateHint, r.adminDeleteHint)
+
+	r.registerEndpoint("/1.0/scores", "admin:scores", r.adminGetScores, r.adminCreateScore, nil, r.adminClearScores)
+	r.registerEndpoint("/1.0/scores/{id}", "admin:scores", r.adminGetScore, nil, r.adminUpdateScore, r.adminDeleteScore)
+
+	r.registerEndpoint("/1.0/submissions", "admin:flags", r.adminGetSubmissions, nil, nil, nil)
+	r.registerEndpoint("/1.0/submissions/near-misses", "admin:flags", r.adminGetNearMisses, nil, nil, nil)
+
+	r.registerEndpoint("/1.0/teams", "admin:teams", r.adminGetTeams, r.adminCreateTeam, nil, r.adminClearTeams)
+	r.registerEndpoint("/1.0/teams/{id}", "admin:teams", r.adminGetTeam, nil, r.adminUpdateTeam, r.adminDeleteTeam)
+	r.registerEndpoint("/1.0/teams/{id}/tokens", "admin:teams", r.adminGetTeamTokens, r.adminCreateTeamToken, nil, r.adminClearTeamTokens)
+	r.registerEndpoint("/1.0/teams/{id}/tokens/{tokenid}", "admin:teams", nil, nil, nil, r.adminDeleteTeamToken)
 
 	// MCP server endpoint (disabled by default)
 	if conf.MCP {
@@ -67,20 +114,23 @@ func AttachFunctions(ctx context.Context, conf *config.Config, router *http.Serv
 		}, nil, nil)
 	}
 
+	// Notify the clients of event start and end
+	go r.scheduleWatcher(ctx)
+
 	// Setup forwarder
 	for _, peer := range conf.Daemon.ClusterPeers {
 		u, err := url.ParseRequestURI(peer)
 		if err != nil {
 			r.logger.Error("Unable to parse peer address", log15.Ctx{"peer": peer, "error": err})
 
-			return err
+			return nil, err
 		}
 
 		host, _, err := net.SplitHostPort(u.Host)
 		if err != nil {
 			r.logger.Error("Unable to parse peer host", log15.Ctx{"peer": peer, "error": err})
 
-			return err
+			return nil, err
 		}
 
 		peerIP := net.ParseIP(strings.Trim(host, "[]"))
@@ -93,7 +143,7 @@ func AttachFunctions(ctx context.Context, conf *config.Config, router *http.Serv
 			if err != nil {
 				r.logger.Error("Unable to resolve peer to addr", log15.Ctx{"peer": peer, "error": err})
 
-				return err
+				return nil, err
 			}
 
 			clusterPeers = append(clusterPeers, addr...)
@@ -102,22 +152,46 @@ func AttachFunctions(ctx context.Context, conf *config.Config, router *http.Serv
 		go r.forwardEvents(peer)
 	}
 
-	return nil
+	return r.isTrustedProxy, nil
 }
 
 func (r *rest) registerEndpoint(u string, access string, funcGet, funcPost, funcPut, funcDelete func(writer http.ResponseWriter, request *http.Request, logger log15.Logger)) {
+	level, scope, _ := strings.Cut(access, ":")
+
+	// Keep a record of all admin changes (URLs not ending with an ID being collections)
+	if level == "admin" {
+		collection := !strings.HasSuffix(u, "}")
+
+		funcPost = r.auditChanges(funcGet, funcPost, collection)
+		funcPut = r.auditChanges(funcGet, funcPut, collection)
+		funcDelete = r.auditChanges(funcGet, funcDelete, collection)
+	}
+
 	r.router.HandleFunc(u, func(writer http.ResponseWriter, request *http.Request) {
 		metricRequests.Inc()
 
-		if !r.hasAccess(access, request) {
+		// Only identify the client once per request
+		request = withRequestIdentity(request)
+
+		r.logger.Debug("Request received", log15.Ctx{"method": request.Method, "url": getLogURL(request), "client": request.RemoteAddr})
+		logger := r.logger.New("method", request.Method, "url", getLogURL(request), "client", request.RemoteAddr)
+
+		if level == "admin" {
+			// Admin endpoints are restricted by role and attributed to a principal
+			principal, role := r.getAdminPrincipal(request)
+			if !adminAllowed(role, scope, request.Method) {
+				r.errorResponse(403, "Forbidden", writer, request)
+
+				return
+			}
+
+			logger = logger.New("principal", principal)
+		} else if !r.hasAccess(level, request) {
 			r.errorResponse(403, "Forbidden", writer, request)
 
 			return
 		}
 
-		r.logger.Debug("Request received", log15.Ctx{"method": request.Method, "url": request.URL, "client": request.RemoteAddr})
-		logger := r.logger.New("method", request.Method, "url", request.URL, "client", request.RemoteAddr)
-
 		// Process the Origin header
 		r.processOrigin(writer, request)
 
@@ -154,7 +228,7 @@ func (r *rest) registerEndpoint(u string, access string, funcGet, funcPost, func
 		default:
 		}
 
-		r.logger.Info("Bad request (not implemented)", log15.Ctx{"method": request.Method, "url": request.URL, "client": request.RemoteAddr})
+		r.logger.Info("Bad request (not implemented)", log15.Ctx{"method": request.Method, "url": getLogURL(request), "client": request.RemoteAddr})
 		r.errorResponse(501, "Not Implemented", writer, request)
 	})
 }
@@ -0,0 +1,143 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/nsec/askgod/api"
+)
+
+// auditWriter keeps track of the status of the response to an audited request.
+type auditWriter struct {
+	http.ResponseWriter
+
+	status int
+}
+
+func (w *auditWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// auditChanges wraps an admin endpoint function so that all its calls get recorded in the audit log, failed ones included.
+// The state before and after the change is retrieved through the GET function of the endpoint.
+// Only the number of entries is recorded when deleting a whole collection.
+func (r *rest) auditChanges(funcGet, funcChange func(writer http.ResponseWriter, request *http.Request, logger log15.Logger), collection bool) func(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	if funcChange == nil {
+		return nil
+	}
+
+	return func(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+		entry := api.AdminAuditEntry{
+			Method: request.Method,
+			URL:    getLogURL(request),
+		}
+
+		summarize := collection && request.Method == http.MethodDelete
+
+		// Get the previous state
+		if request.Method != http.MethodPost {
+			entry.Before = r.auditState(funcGet, request, logger, summarize)
+		}
+
+		// Keep a copy of the input
+		request.Body = http.MaxBytesReader(writer, request.Body, 1024*1024)
+
+		input, err := io.ReadAll(request.Body)
+		if err != nil {
+			logger.Warn("Failed to read the request", log15.Ctx{"error": err})
+			r.errorResponse(400, "Failed to read the request", writer, request)
+
+			entry.Status = http.StatusBadRequest
+			r.auditRecord(request, logger, entry)
+
+			return
+		}
+
+		request.Body = io.NopCloser(bytes.NewReader(input))
+
+		// Process the request
+		recorder := &auditWriter{ResponseWriter: writer, status: http.StatusOK}
+		funcChange(recorder, request, logger)
+
+		entry.Status = recorder.status
+
+		// Get the new state
+		if request.Method == http.MethodPost {
+			if json.Valid(input) {
+				buf := bytes.Buffer{}
+
+				err := json.Compact(&buf, input)
+				if err == nil {
+					entry.After = buf.Bytes()
+				}
+			}
+		} else {
+			entry.After = r.auditState(funcGet, request, logger, summarize)
+		}
+
+		r.auditRecord(request, logger, entry)
+	}
+}
+
+// auditRecord adds the entry to the audit log, attributing it to the principal of the request.
+func (r *rest) auditRecord(request *http.Request, logger log15.Logger, entry api.AdminAuditEntry) {
+	entry.Principal, _ = r.getAdminPrincipal(request)
+
+	ip, err := r.getIP(request)
+	if err == nil {
+		entry.IP = ip.String()
+	}
+
+	// Record the entry even if the client went away
+	_, err = r.db.CreateAuditEntry(context.WithoutCancel(request.Context()), entry)
+	if err != nil {
+		logger.Error("Failed to record the audit entry", log15.Ctx{"error": err})
+	}
+}
+
+// auditState returns the current state of the target of an admin request, as returned by the endpoint's GET function.
+// With summarize, only the number of entries of a collection is returned.
+func (r *rest) auditState(funcGet func(writer http.ResponseWriter, request *http.Request, logger log15.Logger), request *http.Request, logger log15.Logger, summarize bool) json.RawMessage {
+	if funcGet == nil {
+		return nil
+	}
+
+	getRequest := request.Clone(context.WithoutCancel(request.Context()))
+	getRequest.Method = http.MethodGet
+	getRequest.Body = http.NoBody
+
+	rec := httptest.NewRecorder()
+	funcGet(rec, getRequest, logger)
+
+	if rec.Code != http.StatusOK {
+		return nil
+	}
+
+	if summarize {
+		entries := []json.RawMessage{}
+
+		err := json.Unmarshal(rec.Body.Bytes(), &entries)
+		if err != nil {
+			return nil
+		}
+
+		return json.RawMessage(fmt.Sprintf(`{"count":%d}`, len(entries)))
+	}
+
+	buf := bytes.Buffer{}
+
+	err := json.Compact(&buf, rec.Body.Bytes())
+	if err != nil {
+		return nil
+	}
+
+	return buf.Bytes()
+}
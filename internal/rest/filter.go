@@ -0,0 +1,66 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nsec/askgod/api"
+	"github.com/nsec/askgod/internal/database"
+)
+
+// scoreFilter returns the score filter to apply for the request.
+func (r *rest) scoreFilter(request *http.Request) (database.ScoreFilter, error) {
+	filter := database.ScoreFilter{}
+	isAdmin := r.hasAccess("admin", request)
+
+	// Restrict to a single tag
+	tag := request.FormValue("tag")
+	if tag != "" {
+		fields := strings.SplitN(tag, ":", 2)
+		if len(fields) != 2 {
+			return filter, errors.New("invalid tag")
+		}
+
+		// Only public tags may be used by non-admins
+		if !isAdmin && !slices.Contains(r.config.Scoring.PublicTags, fields[0]) {
+			return filter, errors.New("invalid tag")
+		}
+
+		filter.Tag = tag
+	}
+
+	// Compute the standings as of a given time
+	at := request.FormValue("at")
+	if at != "" {
+		atTime, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return filter, err
+		}
+
+		filter.At = atTime
+	}
+
+	// Restrict to a single division
+	filter.Division = request.FormValue("division")
+
+	// Don't let later solves of dynamic flags leak into past values
+	filter.DynamicValues = r.config.Scoring.Mode == api.ScoringModeDynamic
+
+	// Apply the scoreboard freeze
+	if r.isFrozen() && !isAdmin {
+		filter.Before = r.config.Scoring.FreezeAt
+
+		// Teams still get to see their own score changes
+		if r.hasAccess("team", request) {
+			team, err := r.getRequestTeam(request)
+			if err == nil {
+				filter.TeamID = team.ID
+			}
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,182 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/inconshreveable/log15"
+
+	"github.com/nsec/askgod/api"
+)
+
+// frozenEvent is a score event held back from the public while the scoreboard was frozen.
+type frozenEvent struct {
+	eventType string
+	teamid    int64
+	division  string
+	body      []byte
+}
+
+func (r *rest) isFrozen() bool {
+	return scoringFrozen(r.config.Scoring)
+}
+
+// scoringFrozen checks whether the scoreboard freeze of the scoring configuration is in effect.
+func scoringFrozen(scoring api.ConfigScoring) bool {
+	return !scoring.FreezeAt.IsZero() && time.Now().After(scoring.FreezeAt)
+}
+
+// eventReplayFrozen sends the score changes held back since the freeze to the listeners which didn't get them.
+// The events are rebuilt from the database so none get lost if the server restarted during the freeze.
+func (r *rest) eventReplayFrozen(ctx context.Context, freezeAt time.Time) {
+	entries, err := r.db.GetScoreEvents(ctx, freezeAt)
+	if err != nil {
+		r.logger.Error("Failed to get the score changes held back by the freeze", log15.Ctx{"error": err})
+
+		return
+	}
+
+	if len(entries) == 0 {
+		return
+	}
+
+	// Rebuild the events
+	events := []frozenEvent{}
+	teams := map[int64]*api.AdminTeam{}
+	flags := map[int64]*api.AdminFlag{}
+
+	for _, entry := range entries {
+		team, ok := teams[entry.TeamID]
+		if !ok {
+			team, err = r.db.GetTeam(ctx, entry.TeamID)
+			if err != nil {
+				r.logger.Error("Failed to get the team", log15.Ctx{"error": err, "teamid": entry.TeamID})
+
+				continue
+			}
+
+			teams[entry.TeamID] = team
+		}
+
+		tags := map[string]string{}
+
+		if entry.FlagID > 0 {
+			flag, ok := flags[entry.FlagID]
+			if !ok {
+				flag, err = r.db.GetFlag(ctx, entry.FlagID)
+				if err != nil {
+					r.logger.Error("Failed to get the flag", log15.Ctx{"error": err, "flagid": entry.FlagID})
+
+					continue
+				}
+
+				flags[entry.FlagID] = flag
+			}
+
+			for key, value := range flag.Tags {
+				if slices.Contains(r.config.Scoring.PublicTags, key) {
+					tags[key] = value
+				}
+			}
+		}
+
+		if entry.Type == api.ScoreTypeBonus {
+			body, err := json.Marshal(newEvent("first-blood", api.EventFirstBlood{TeamID: team.ID, Team: &team.TeamPut, Division: team.Division, Rank: entry.Rank, Value: entry.Value, Tags: tags}))
+			if err == nil {
+				events = append(events, frozenEvent{eventType: "first-blood", teamid: team.ID, division: team.Division, body: body})
+			}
+		}
+
+		score := api.TimelineEntryScore{
+			SubmitTime: entry.SubmitTime,
+			Value:      entry.Value,
+			Total:      entry.Total,
+			Tags:       tags,
+		}
+
+		body, err := json.Marshal(newEvent("timeline", api.EventTimeline{TeamID: team.ID, Team: &team.TeamPut, Division: team.Division, Score: &score, Type: "score-updated"}))
+		if err == nil {
+			events = append(events, frozenEvent{eventType: "timeline", teamid: team.ID, division: team.Division, body: body})
+		}
+	}
+
+	r.logger.Info("Replaying events held back by the scoreboard freeze", log15.Ctx{"count": len(events)})
+
+	eventsLock.Lock()
+	listeners := make([]*eventListener, 0, len(eventListeners))
+
+	for _, listener := range eventListeners {
+		listeners = append(listeners, listener)
+	}
+	eventsLock.Unlock()
+
+	for _, event := range events {
+		for _, listener := range listeners {
+			// Peers replay their own events and admins/teams already got theirs
+			if listener.peer || listener.teamid == -1 || listener.teamid == event.teamid {
+				continue
+			}
+
+			if listener.messageTypes != nil && !slices.Contains(listener.messageTypes, event.eventType) {
+				continue
+			}
+
+			if !r.eventVisible(listener, event.teamid) {
+				continue
+			}
+
+			if listener.division != "" && event.division != listener.division {
+				continue
+			}
+
+			listener.msgLock.Lock()
+			err := listener.connection.WriteMessage(websocket.TextMessage, event.body)
+			listener.msgLock.Unlock()
+
+			if err != nil {
+				select {
+				case listener.active <- false:
+				default:
+				}
+			}
+		}
+	}
+}
+
+func (r *rest) revealScoreboard(writer http.ResponseWriter, request *http.Request, logger log15.Logger) {
+	if r.config.Scoring.FreezeAt.IsZero() {
+		logger.Warn("Reveal requested while the scoreboard isn't frozen")
+		r.errorResponse(400, "The scoreboard isn't frozen", writer, request)
+
+		return
+	}
+
+	// Lift the freeze
+	freezeAt := r.config.Scoring.FreezeAt
+	newConfig := r.config.ConfigPut
+	newConfig.Scoring.FreezeAt = time.Time{}
+
+	err := r.db.UpdateConfig(request.Context(), newConfig)
+	if err != nil {
+		logger.Error("Failed to update the configuration", log15.Ctx{"error": err})
+		r.errorResponse(500, fmt.Sprintf("%v", err), writer, request)
+
+		return
+	}
+
+	_ = r.eventSend(request.Context(), "internal", api.EventInternal{Type: "config-updated"})
+	r.config.ConfigPut = newConfig
+
+	// Replay the hidden events
+	r.eventReplayFrozen(request.Context(), freezeAt)
+
+	logger.Info("Scoreboard revealed")
+
+	// Tell everyone to reload
+	_ = r.eventSend(request.Context(), "timeline", api.EventTimeline{Type: "reload"})
+}
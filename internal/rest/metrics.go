@@ -19,3 +19,11 @@ var metricSubmitTeam = promauto.NewCounterVec(
 	},
 	[]string{"team_id", "type"},
 )
+
+var metricSubmitRateLimited = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "askgod_submit_ratelimited_total",
+		Help: "Flag submissions rejected by the rate limiter per team and scope",
+	},
+	[]string{"team_id", "scope"},
+)
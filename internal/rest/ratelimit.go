@@ -0,0 +1,105 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/nsec/askgod/api"
+	"github.com/nsec/askgod/internal/database"
+)
+
+type rateLimit struct {
+	scope string
+	key   string
+	limit int64
+}
+
+// validateRateLimit checks that the rate limit configuration is usable.
+func validateRateLimit(scoring api.ConfigScoring) error {
+	if scoring.RateLimitTeam < 0 || scoring.RateLimitIP < 0 || scoring.RateLimitWindow < 0 || scoring.RateLimitCooldown < 0 {
+		return errors.New("rate limit values can't be negative")
+	}
+
+	if (scoring.RateLimitTeam > 0 || scoring.RateLimitIP > 0) && scoring.RateLimitCooldown <= 0 {
+		return errors.New("rate limit cooldown must be set along with the attempt limits")
+	}
+
+	maxCooldown := int64(database.RateLimitMaxLockout / time.Second)
+	if scoring.RateLimitCooldown > maxCooldown {
+		return fmt.Errorf("rate limit cooldown can't exceed %d seconds", maxCooldown)
+	}
+
+	return nil
+}
+
+// rateLimits returns the rate limits which apply to a submission from the team and IP.
+func (r *rest) rateLimits(teamID int64, ip net.IP) []rateLimit {
+	limits := []rateLimit{}
+
+	if r.config.Scoring.RateLimitWindow <= 0 {
+		return limits
+	}
+
+	if r.config.Scoring.RateLimitTeam > 0 {
+		limits = append(limits, rateLimit{scope: "team", key: "team:" + strconv.FormatInt(teamID, 10), limit: r.config.Scoring.RateLimitTeam})
+	}
+
+	if r.config.Scoring.RateLimitIP > 0 {
+		limits = append(limits, rateLimit{scope: "ip", key: "ip:" + ip.String(), limit: r.config.Scoring.RateLimitIP})
+	}
+
+	return limits
+}
+
+// checkRateLimit returns true (after responding to the client) if the team or IP is currently locked out.
+func (r *rest) checkRateLimit(writer http.ResponseWriter, request *http.Request, logger log15.Logger, teamID int64, ip net.IP) bool {
+	for _, limit := range r.rateLimits(teamID, ip) {
+		remaining, err := r.db.GetRateLimit(request.Context(), limit.key)
+		if err != nil {
+			logger.Error("Failed to get the rate limit state", log15.Ctx{"error": err, "key": limit.key})
+
+			continue
+		}
+
+		if remaining <= 0 {
+			continue
+		}
+
+		metricSubmitRateLimited.WithLabelValues(strconv.FormatInt(teamID, 10), limit.scope).Inc()
+		logger.Info("Rate limited flag submission", log15.Ctx{"teamid": teamID, "ip": ip.String(), "scope": limit.scope, "remaining": remaining})
+
+		writer.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(remaining.Seconds())), 10))
+		r.errorResponse(429, "Too many invalid submissions, try again later", writer, request)
+
+		return true
+	}
+
+	return false
+}
+
+// recordInvalidSubmission counts an invalid submission towards the rate limits of the team and IP.
+func (r *rest) recordInvalidSubmission(ctx context.Context, logger log15.Logger, teamID int64, ip net.IP) {
+	window := time.Duration(r.config.Scoring.RateLimitWindow) * time.Second
+	cooldown := time.Duration(r.config.Scoring.RateLimitCooldown) * time.Second
+
+	for _, limit := range r.rateLimits(teamID, ip) {
+		lockout, err := r.db.RecordRateLimitAttempt(ctx, limit.key, limit.limit, window, cooldown)
+		if err != nil {
+			logger.Error("Failed to record the invalid submission", log15.Ctx{"error": err, "key": limit.key})
+
+			continue
+		}
+
+		if lockout > 0 {
+			logger.Warn("Flag submissions locked out", log15.Ctx{"teamid": teamID, "ip": ip.String(), "scope": limit.scope, "duration": lockout})
+		}
+	}
+}
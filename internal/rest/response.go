@@ -19,6 +19,7 @@ func (r *rest) processOrigin(writer http.ResponseWriter, request *http.Request)
 
 		writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		writer.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		writer.Header().Set("Access-Control-Expose-Headers", "Retry-After")
 	}
 }
 
@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"context"
+	"time"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/nsec/askgod/api"
+)
+
+// eventState returns the current state of the event and the time left until it changes.
+func (r *rest) eventState() (string, time.Duration) {
+	now := time.Now()
+
+	if !r.config.Scoring.StartTime.IsZero() && now.Before(r.config.Scoring.StartTime) {
+		return api.EventStatePending, r.config.Scoring.StartTime.Sub(now)
+	}
+
+	if !r.config.Scoring.EndTime.IsZero() {
+		if now.Before(r.config.Scoring.EndTime) {
+			return api.EventStateRunning, r.config.Scoring.EndTime.Sub(now)
+		}
+
+		return api.EventStateEnded, 0
+	}
+
+	return api.EventStateRunning, 0
+}
+
+// scheduleWatcher tells the clients to reload whenever the event starts or ends.
+// Every cluster member runs its own watcher so the reload isn't forwarded to peers.
+func (r *rest) scheduleWatcher(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	state, _ := r.eventState()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		newState, _ := r.eventState()
+		if newState == state {
+			continue
+		}
+
+		r.logger.Info("Event state changed", log15.Ctx{"old": state, "new": newState})
+		state = newState
+
+		_ = r.eventSendLocal(ctx, "timeline", api.EventTimeline{Type: "reload"})
+	}
+}
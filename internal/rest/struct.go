@@ -2,6 +2,7 @@ package rest
 
 import (
 	"net/http"
+	"sync/atomic"
 
 	"github.com/inconshreveable/log15"
 
@@ -15,4 +16,5 @@ type rest struct {
 	logger      log15.Logger
 	router      *http.ServeMux
 	hiddenTeams []int64
+	acl         atomic.Pointer[acl]
 }
@@ -0,0 +1,2 @@
+// Package subnets provides a prefix tree index of IP subnets
+package subnets
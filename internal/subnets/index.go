@@ -0,0 +1,159 @@
+package subnets
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ErrOverlap indicates that a subnet overlaps with one belonging to another owner.
+var ErrOverlap = errors.New("overlapping subnets")
+
+// Index maps IP subnets to the ID of their owner.
+//
+// Subnets of different owners may not overlap, making any match unique.
+type Index struct {
+	ipv4 *node
+	ipv6 *node
+}
+
+type node struct {
+	children [2]*node
+	subnet   *net.IPNet
+	owner    int64
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{ipv4: &node{}, ipv6: &node{}}
+}
+
+// Parse converts a comma separated list of subnets.
+func Parse(list string) ([]*net.IPNet, error) {
+	resp := []*net.IPNet{}
+
+	for entry := range strings.SplitSeq(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		_, subnet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = append(resp, subnet)
+	}
+
+	return resp, nil
+}
+
+// root returns the tree and the address bytes to use for the IP.
+func (idx *Index) root(ip net.IP) (*node, net.IP) {
+	ipv4 := ip.To4()
+	if ipv4 != nil {
+		return idx.ipv4, ipv4
+	}
+
+	return idx.ipv6, ip.To16()
+}
+
+// Add records the subnet as belonging to the owner.
+func (idx *Index) Add(subnet *net.IPNet, owner int64) error {
+	current, ip := idx.root(subnet.IP)
+	if ip == nil {
+		return fmt.Errorf("invalid subnet: %s", subnet)
+	}
+
+	ones, _ := subnet.Mask.Size()
+	if len(subnet.Mask) == net.IPv6len && len(ip) == net.IPv4len {
+		// IPv4 subnet with an IPv6 mask
+		ones -= 96
+	}
+
+	// Walk down the tree, checking for larger subnets
+	for i := range ones {
+		if current.subnet != nil && current.owner != owner {
+			return fmt.Errorf("%w: %s is part of %s (owned by %d)", ErrOverlap, subnet, current.subnet, current.owner)
+		}
+
+		bit := (ip[i/8] >> (7 - i%8)) & 1
+		if current.children[bit] == nil {
+			current.children[bit] = &node{}
+		}
+
+		current = current.children[bit]
+	}
+
+	// Check for the subnet itself and smaller subnets
+	conflict := current.find(func(entry *node) bool {
+		return entry.subnet != nil && entry.owner != owner
+	})
+
+	if conflict != nil {
+		return fmt.Errorf("%w: %s contains %s (owned by %d)", ErrOverlap, subnet, conflict.subnet, conflict.owner)
+	}
+
+	if current.subnet == nil {
+		current.subnet = subnet
+		current.owner = owner
+	}
+
+	return nil
+}
+
+// find returns the first node of the subtree matching the function.
+func (n *node) find(match func(*node) bool) *node {
+	if match(n) {
+		return n
+	}
+
+	for _, child := range n.children {
+		if child == nil {
+			continue
+		}
+
+		entry := child.find(match)
+		if entry != nil {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+// Lookup returns the owner of the subnet containing the IP.
+func (idx *Index) Lookup(ip net.IP) (int64, bool) {
+	current, addr := idx.root(ip)
+	if addr == nil {
+		return -1, false
+	}
+
+	for i := range len(addr) * 8 {
+		if current.subnet != nil {
+			return current.owner, true
+		}
+
+		bit := (addr[i/8] >> (7 - i%8)) & 1
+
+		current = current.children[bit]
+		if current == nil {
+			return -1, false
+		}
+	}
+
+	if current.subnet != nil {
+		return current.owner, true
+	}
+
+	return -1, false
+}
+
+// Contains checks whether the IP is part of any subnet of the index.
+func (idx *Index) Contains(ip net.IP) bool {
+	_, ok := idx.Lookup(ip)
+
+	return ok
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Reasons returned by NearMiss.
+const (
+	NearMissWhitespace = "whitespace"
+	NearMissPrefix     = "prefix"
+	NearMissTypo       = "typo"
+)
+
+// nearMissMinLength is the shortest flag considered for near-miss detection.
+const nearMissMinLength = 6
+
+// NearMissMaxLength is the longest input (or flag) in bytes considered for near-miss detection.
+const NearMissMaxLength = 256
+
+// NearMiss checks whether the input is almost the given flag (case is ignored).
+// It returns the reason and edit distance of the near miss, or an empty reason if none.
+func NearMiss(input string, flag string) (string, int) {
+	if len(input) > NearMissMaxLength || len(flag) > NearMissMaxLength {
+		return "", 0
+	}
+
+	input = strings.ToLower(input)
+	flag = strings.ToLower(flag)
+
+	if len([]rune(flag)) < nearMissMinLength || input == flag {
+		return "", 0
+	}
+
+	// Extra or missing whitespace
+	if stripSpaces(input) == stripSpaces(flag) {
+		return NearMissWhitespace, 0
+	}
+
+	// Missing prefix or wrapper (e.g. the content of FLAG{...})
+	if len(input) >= nearMissMinLength && len(input)*2 >= len(flag) && strings.HasSuffix(strings.TrimSuffix(flag, "}"), input) {
+		return NearMissPrefix, 0
+	}
+
+	// Typos (the length difference alone is a lower bound of the distance)
+	inputLength := len([]rune(input))
+	flagLength := len([]rune(flag))
+	threshold := max(1, flagLength/8)
+
+	if inputLength > flagLength+threshold || flagLength > inputLength+threshold {
+		return "", 0
+	}
+
+	distance := EditDistance(input, flag)
+	if distance <= threshold {
+		return NearMissTypo, distance
+	}
+
+	return "", 0
+}
+
+// EditDistance returns the Levenshtein distance between two strings.
+func EditDistance(a string, b string) int {
+	runesA := []rune(a)
+	runesB := []rune(b)
+
+	previous := make([]int, len(runesB)+1)
+	current := make([]int, len(runesB)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := range runesA {
+		current[0] = i + 1
+
+		for j := range runesB {
+			cost := 1
+			if runesA[i] == runesB[j] {
+				cost = 0
+			}
+
+			current[j+1] = min(previous[j+1]+1, current[j]+1, previous[j]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(runesB)]
+}
+
+func stripSpaces(in string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+
+		return r
+	}, in)
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"crypto/tls"
 	"fmt"
 	"os"
 	"slices"
@@ -17,6 +18,35 @@ func PathExists(path string) bool {
 	return true
 }
 
+// ReadPEM returns the PEM content of a configuration value, which may be either the content itself or the path to a file.
+func ReadPEM(value string) (string, error) {
+	if strings.Contains(value, "\n") || !PathExists(value) {
+		return value, nil
+	}
+
+	content, err := os.ReadFile(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// ReadKeyPair loads a certificate and its key from configuration values (see ReadPEM).
+func ReadKeyPair(certValue string, keyValue string) (tls.Certificate, error) {
+	cert, err := ReadPEM(certValue)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	key, err := ReadPEM(keyValue)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.X509KeyPair([]byte(cert), []byte(key))
+}
+
 // ParseTags converts a serialized tags list to map[string]string.
 func ParseTags(in string) (map[string]string, error) {
 	out := map[string]string{}